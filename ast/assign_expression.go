@@ -0,0 +1,35 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// AssignExpression присваивает значение переменной или элементу
+// массива/словаря: x = 1, мас[i] += 2
+type AssignExpression struct {
+	Token    token.Token // token.ASSIGN или составное присваивание
+	Target   Expression  // *Identifier или *IndexExpression
+	Operator string
+	Value    Expression
+}
+
+func (a *AssignExpression) expressionNode() {}
+func (a *AssignExpression) TokenLiteral() string {
+	return a.Token.Literal
+}
+func (a *AssignExpression) Pos() token.Position {
+	return a.Token.Pos
+}
+func (a *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(a.Target.String())
+	out.WriteString(" " + a.Operator + " ")
+	out.WriteString(a.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
@@ -9,6 +9,7 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // позиция первого токена узла
 }
 
 type Statement interface {
@@ -31,6 +32,12 @@ func (p *Program) TokenLiteral() string {
 	}
 	return ""
 }
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -49,6 +56,9 @@ type Identifier struct {
 func (id *Identifier) TokenLiteral() string {
 	return id.Token.Literal
 }
+func (id *Identifier) Pos() token.Position {
+	return id.Token.Pos
+}
 func (id *Identifier) String() string {
 	return id.Value
 }
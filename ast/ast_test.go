@@ -12,8 +12,8 @@ func TestAst(t *testing.T) {
 		Statements: []Statement{
 			&VariableStatement{
 				Token: token.Token{
-					Type:    token.IDENT,
-					Literal: "test",
+					Type:    token.LET,
+					Literal: "создать",
 				},
 				Ident: &Identifier{
 					Token: token.Token{
@@ -35,7 +35,7 @@ func TestAst(t *testing.T) {
 	}
 
 	log.Println(p.String())
-	if p.String() != `test: строка = "тест";` {
+	if p.String() != `создать test: строка = "тест";` {
 		t.Fatalf("got %q", p.String())
 	}
 
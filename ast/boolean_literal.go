@@ -11,6 +11,9 @@ func (b *BooleanLiteral) expressionNode() {}
 func (b *BooleanLiteral) TokenLiteral() string {
 	return b.Token.Literal
 }
+func (b *BooleanLiteral) Pos() token.Position {
+	return b.Token.Pos
+}
 func (b *BooleanLiteral) String() string {
 	return b.Token.Literal
 }
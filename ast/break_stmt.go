@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// BreakStatement завершает ближайший цикл или цикл с меткой Label
+type BreakStatement struct {
+	Token token.Token // token.BREAK
+	Label *Identifier
+}
+
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+func (bs *BreakStatement) Pos() token.Position {
+	return bs.Token.Pos
+}
+func (bs *BreakStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(bs.TokenLiteral())
+
+	if bs.Label != nil {
+		out.WriteString(" " + bs.Label.String())
+	}
+
+	out.WriteString(";")
+	return out.String()
+}
+func (bs *BreakStatement) statementNode() {}
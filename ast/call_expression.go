@@ -17,6 +17,11 @@ func (c *CallExpression) TokenLiteral() string {
 	return c.Token.Literal
 }
 
+// Pos указывает на вызываемую функцию, а не на скобку
+func (c *CallExpression) Pos() token.Position {
+	return c.Function.Pos()
+}
+
 func (c *CallExpression) String() string {
 	var out bytes.Buffer
 
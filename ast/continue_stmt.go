@@ -0,0 +1,34 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// ContinueStatement переходит к следующей итерации ближайшего цикла
+// или цикла с меткой Label
+type ContinueStatement struct {
+	Token token.Token // token.CONTINUE
+	Label *Identifier
+}
+
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+func (cs *ContinueStatement) Pos() token.Position {
+	return cs.Token.Pos
+}
+func (cs *ContinueStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.TokenLiteral())
+
+	if cs.Label != nil {
+		out.WriteString(" " + cs.Label.String())
+	}
+
+	out.WriteString(";")
+	return out.String()
+}
+func (cs *ContinueStatement) statementNode() {}
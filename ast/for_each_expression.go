@@ -0,0 +1,47 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// ForEachExpression перебирает элементы коллекции:
+// цикл (x в мас) { ... } или цикл (i, x в мас) { ... }
+type ForEachExpression struct {
+	Token     token.Token // token.FOR
+	Label     *Identifier // метка для прервать/продолжить, может отсутствовать
+	Index     *Identifier // индекс элемента или ключ словаря, может отсутствовать
+	Value     *Identifier
+	Iterable  Expression
+	Statement *BlockStatement
+}
+
+func (f *ForEachExpression) TokenLiteral() string {
+	return f.Token.Literal
+}
+func (f *ForEachExpression) Pos() token.Position {
+	return f.Token.Pos
+}
+
+func (f *ForEachExpression) String() string {
+	var out bytes.Buffer
+
+	if f.Label != nil {
+		out.WriteString(f.Label.String() + ": ")
+	}
+	out.WriteString("цикл ")
+	out.WriteString("(")
+	if f.Index != nil {
+		out.WriteString(f.Index.String() + ", ")
+	}
+	out.WriteString(f.Value.String())
+	out.WriteString(" в ")
+	out.WriteString(f.Iterable.String())
+	out.WriteString(")")
+	out.WriteString(f.Statement.String())
+
+	return out.String()
+}
+
+func (f *ForEachExpression) expressionNode() {}
@@ -2,26 +2,51 @@ package ast
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/usamaroman/uman/token"
 )
 
+// ForLoopExpression - цикл с условием: цикл (условие) { ... }
+// или с тремя частями: цикл (создать i: число = 0; i < 10; i += 1) { ... }
 type ForLoopExpression struct {
 	Token     token.Token // token.FOR
+	Label     *Identifier // метка для прервать/продолжить, может отсутствовать
+	Init      Statement   // выполняется один раз до цикла, может отсутствовать
 	Condition Expression
+	Post      Expression // выполняется после каждой итерации, может отсутствовать
 	Statement *BlockStatement
 }
 
 func (f *ForLoopExpression) TokenLiteral() string {
 	return f.Token.Literal
 }
+func (f *ForLoopExpression) Pos() token.Position {
+	return f.Token.Pos
+}
 
 func (f *ForLoopExpression) String() string {
 	var out bytes.Buffer
 
+	if f.Label != nil {
+		out.WriteString(f.Label.String() + ": ")
+	}
 	out.WriteString("цикл ")
 	out.WriteString("(")
+	threePart := f.Init != nil || f.Post != nil
+	if f.Init != nil {
+		out.WriteString(strings.TrimSuffix(f.Init.String(), ";"))
+	}
+	if threePart {
+		out.WriteString("; ")
+	}
 	out.WriteString(f.Condition.String())
+	if threePart {
+		out.WriteString("; ")
+	}
+	if f.Post != nil {
+		out.WriteString(f.Post.String())
+	}
 	out.WriteString(")")
 	out.WriteString(f.Statement.String())
 
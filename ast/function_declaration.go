@@ -0,0 +1,36 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// FunctionDeclaration объявляет именованную функцию: функция имя(x) { ... }.
+// Объявления поднимаются в начало своей области видимости
+type FunctionDeclaration struct {
+	Token    token.Token // token.FUNCTION
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (fd *FunctionDeclaration) TokenLiteral() string {
+	return fd.Token.Literal
+}
+func (fd *FunctionDeclaration) Pos() token.Position {
+	return fd.Token.Pos
+}
+func (fd *FunctionDeclaration) String() string {
+	var out bytes.Buffer
+
+	f := fd.Function
+	out.WriteString(fd.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(fd.Name.String())
+	out.WriteString(Signature(f.Arguments, f.ArgumentTypes, f.Defaults, f.Variadic, f.ReturnType))
+	out.WriteString(" ")
+	out.WriteString(f.Body.String())
+
+	return out.String()
+}
+func (fd *FunctionDeclaration) statementNode() {}
@@ -8,29 +8,60 @@ import (
 )
 
 type FunctionLiteral struct {
-	Token     token.Token
-	Arguments []*Identifier
-	Body      *BlockStatement
+	Token         token.Token
+	Arguments     []*Identifier
+	ArgumentTypes []token.TokenType // тип каждого аргумента, "" если тип не указан
+	Defaults      []Expression      // значение по умолчанию для каждого аргумента или nil
+	Variadic      bool              // последний аргумент собирает остальные значения в массив
+	ReturnType    token.TokenType   // "" если тип не указан
+	Body          *BlockStatement
 }
 
 func (f *FunctionLiteral) expressionNode() {}
 func (f *FunctionLiteral) TokenLiteral() string {
 	return f.Token.Literal
 }
+func (f *FunctionLiteral) Pos() token.Position {
+	return f.Token.Pos
+}
 
 func (f *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
+	out.WriteString(f.TokenLiteral())
+	out.WriteString(Signature(f.Arguments, f.ArgumentTypes, f.Defaults, f.Variadic, f.ReturnType))
+	out.WriteString(" ")
+	out.WriteString(f.Body.String())
+
+	return out.String()
+}
+
+// Signature записывает аргументы и тип результата функции: (x: число, y = 1, ...z): булев
+func Signature(arguments []*Identifier, argumentTypes []token.TokenType, defaults []Expression, variadic bool, returnType token.TokenType) string {
+	var out bytes.Buffer
+
 	args := make([]string, 0)
-	for _, arg := range f.Arguments {
-		args = append(args, arg.String())
+	for i, arg := range arguments {
+		str := arg.String()
+		if variadic && i == len(arguments)-1 {
+			str = "..." + str
+		}
+		if i < len(argumentTypes) && argumentTypes[i] != "" {
+			str += ": " + token.Keyword(argumentTypes[i])
+		}
+		if i < len(defaults) && defaults[i] != nil {
+			str += " = " + defaults[i].String()
+		}
+		args = append(args, str)
 	}
 
-	out.WriteString(f.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
-	out.WriteString(") ")
-	out.WriteString(f.Body.String())
+	out.WriteString(")")
+
+	if returnType != "" {
+		out.WriteString(": " + token.Keyword(returnType))
+	}
 
 	return out.String()
 }
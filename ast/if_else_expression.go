@@ -10,12 +10,16 @@ type IfExpression struct {
 	Token       token.Token
 	Condition   Expression
 	Consequence *BlockStatement
+	ElseIf      *IfExpression // ветка "иначе если", может отсутствовать
 	Alternative *BlockStatement
 }
 
 func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+func (ie *IfExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
 
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
@@ -25,6 +29,11 @@ func (ie *IfExpression) String() string {
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
 
+	if ie.ElseIf != nil {
+		out.WriteString(" иначе ")
+		out.WriteString(ie.ElseIf.String())
+	}
+
 	if ie.Alternative != nil {
 		out.WriteString(" иначе ")
 		out.WriteString(ie.Alternative.String())
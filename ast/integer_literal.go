@@ -1,16 +1,24 @@
 package ast
 
-import "github.com/usamaroman/uman/token"
+import (
+	"math/big"
+
+	"github.com/usamaroman/uman/token"
+)
 
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // не nil, если литерал не помещается в int64
 }
 
 func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
+func (il *IntegerLiteral) Pos() token.Position {
+	return il.Token.Pos
+}
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
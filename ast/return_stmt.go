@@ -11,11 +11,17 @@ import (
 type ReturnStatement struct {
 	Token token.Token // token.RETURN
 	Value Expression
+	// Tail - инструкция стоит в теле функции вне выражений, поэтому ее
+	// результат сразу становится результатом функции. Выставляет парсер
+	Tail bool
 }
 
 func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
+func (rs *ReturnStatement) Pos() token.Position {
+	return rs.Token.Pos
+}
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -12,6 +12,9 @@ type StringLiteral struct {
 func (s StringLiteral) TokenLiteral() string {
 	return s.Token.Literal
 }
+func (s StringLiteral) Pos() token.Position {
+	return s.Token.Pos
+}
 
 func (s StringLiteral) String() string {
 	return s.Token.Literal
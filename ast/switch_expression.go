@@ -0,0 +1,59 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// SwitchExpression выбирает ветку, одно из значений которой равно Subject:
+// выбор (x) { случай 1, 2: ... случай 3: ... по_умолчанию: ... }
+type SwitchExpression struct {
+	Token   token.Token // token.SWITCH
+	Subject Expression
+	Cases   []*SwitchCase
+	Default *BlockStatement // ветка по_умолчанию, может отсутствовать
+}
+
+type SwitchCase struct {
+	Token  token.Token // token.CASE
+	Values []Expression
+	Body   *BlockStatement
+}
+
+func (se *SwitchExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SwitchExpression) Pos() token.Position {
+	return se.Token.Pos
+}
+
+func (se *SwitchExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("выбор ")
+	out.WriteString(se.Subject.String())
+	out.WriteString(" {")
+
+	for _, c := range se.Cases {
+		values := make([]string, 0, len(c.Values))
+		for _, v := range c.Values {
+			values = append(values, v.String())
+		}
+		out.WriteString(" случай ")
+		out.WriteString(strings.Join(values, ", "))
+		out.WriteString(": ")
+		out.WriteString(c.Body.String())
+	}
+
+	if se.Default != nil {
+		out.WriteString(" по_умолчанию: ")
+		out.WriteString(se.Default.String())
+	}
+
+	out.WriteString(" }")
+	return out.String()
+}
+
+func (se *SwitchExpression) expressionNode() {}
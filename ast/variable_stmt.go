@@ -9,7 +9,7 @@ import (
 // VariableStatement variable creates using :
 // implements Statement interface
 type VariableStatement struct {
-	Token    token.Token // token.LET
+	Token    token.Token // token.LET или token.CONST
 	Ident    *Identifier
 	DataType token.TokenType
 	Value    Expression
@@ -18,12 +18,23 @@ type VariableStatement struct {
 func (vs *VariableStatement) TokenLiteral() string {
 	return vs.Token.Literal
 }
+func (vs *VariableStatement) Pos() token.Position {
+	return vs.Token.Pos
+}
+
+// IsConstant сообщает, объявлена ли постоянная
+func (vs *VariableStatement) IsConstant() bool {
+	return vs.Token.Type == token.CONST
+}
 func (vs *VariableStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(vs.TokenLiteral())
+	if vs.Ident != nil {
+		out.WriteString(" " + vs.Ident.String())
+	}
 	out.WriteString(": ")
-	out.WriteString(getDataTypeFromKeywords(vs.DataType))
+	out.WriteString(token.Keyword(vs.DataType))
 	out.WriteString(" = ")
 
 	if vs.Value != nil {
@@ -34,12 +45,3 @@ func (vs *VariableStatement) String() string {
 	return out.String()
 }
 func (vs *VariableStatement) statementNode() {}
-
-func getDataTypeFromKeywords(tokenType token.TokenType) string {
-	for k, v := range token.Keywords {
-		if v == tokenType {
-			return k
-		}
-	}
-	return ""
-}
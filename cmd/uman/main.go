@@ -1,21 +1,63 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"io"
 	"log"
 	"os"
 
+	"github.com/usamaroman/uman/evaluator"
 	"github.com/usamaroman/uman/repl"
 )
 
-func main() {
-	args := os.Args
-	switch len(args) {
+// options - параметры запуска интерпретатора
+type options struct {
+	maxCallDepth int
+	filename     string // пусто, если нужно запустить REPL
+}
+
+// parseArgs разбирает аргументы командной строки без имени программы:
+//
+//	uman [-max-depth N] [файл.um]
+func parseArgs(args []string, output io.Writer) (options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet("uman", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.IntVar(&opts.maxCallDepth, "max-depth", evaluator.DefaultMaxCallDepth,
+		"наибольшее количество вложенных вызовов функций")
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+
+	if opts.maxCallDepth < 1 {
+		return opts, errors.New("max-depth must be positive")
+	}
+
+	switch flags.NArg() {
+	case 0:
 	case 1:
-		repl.Run()
-	case 2:
-		repl.ReadFile(args[1])
+		opts.filename = flags.Arg(0)
 	default:
-		log.Fatal("wrong command")
+		return opts, errors.New("wrong command")
 	}
 
+	return opts, nil
+}
+
+func main() {
+	opts, err := parseArgs(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.filename == "" {
+		repl.Run(opts.maxCallDepth)
+		return
+	}
+	repl.ReadFile(opts.filename, opts.maxCallDepth)
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/usamaroman/uman/evaluator"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args             []string
+		expectedDepth    int
+		expectedFilename string
+	}{
+		{[]string{}, evaluator.DefaultMaxCallDepth, ""},
+		{[]string{"test.um"}, evaluator.DefaultMaxCallDepth, "test.um"},
+		{[]string{"-max-depth", "100"}, 100, ""},
+		{[]string{"-max-depth=50000", "test.um"}, 50000, "test.um"},
+	}
+
+	for _, tt := range tests {
+		opts, err := parseArgs(tt.args, io.Discard)
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", tt.args, err)
+			continue
+		}
+		if opts.maxCallDepth != tt.expectedDepth {
+			t.Errorf("wrong max depth for %v. expected=%d, got=%d", tt.args, tt.expectedDepth, opts.maxCallDepth)
+		}
+		if opts.filename != tt.expectedFilename {
+			t.Errorf("wrong filename for %v. expected=%q, got=%q", tt.args, tt.expectedFilename, opts.filename)
+		}
+	}
+
+	errorTests := [][]string{
+		{"-max-depth", "0"},
+		{"-max-depth", "много"},
+		{"-unknown"},
+		{"a.um", "b.um"},
+		// флаги после имени файла не разбираются
+		{"test.um", "-max-depth", "5"},
+	}
+
+	for _, args := range errorTests {
+		if _, err := parseArgs(args, io.Discard); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
@@ -2,6 +2,10 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/usamaroman/uman/object"
 )
@@ -18,6 +22,8 @@ var builtins = map[string]*object.Builtin{
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
 				return &object.Integer{Value: int64(len(arg.Value))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
 			default:
 				return newError("нельзя передавать в длина(), получено %s",
 					args[0].Type())
@@ -85,8 +91,200 @@ var builtins = map[string]*object.Builtin{
 					args[0].Type())
 			}
 			arr := args[0].(*object.Array)
+			if arr.Frozen {
+				return newError("нельзя изменить постоянный массив")
+			}
 			arr.Elements = append(arr.Elements, args[1])
 			return &object.Array{Elements: arr.Elements}
 		},
 	},
+
+	"в_число": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("неверное количество аргументов получено %d, надо 1",
+					len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInteger:
+				return arg
+			case *object.Float:
+				return floatToInteger(math.Trunc(arg.Value))
+			case *object.String:
+				value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+				if !ok {
+					return newError("нельзя преобразовать %q в число", arg.Value)
+				}
+				return normalizeBigInt(value)
+			default:
+				return newError("нельзя передавать в в_число(), получено %s",
+					args[0].Type())
+			}
+		},
+	},
+
+	"в_дробь": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("неверное количество аргументов получено %d, надо 1",
+					len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInteger:
+				return &object.Float{Value: toFloat(arg)}
+			case *object.Float:
+				return arg
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError("нельзя преобразовать %q в дробь", arg.Value)
+				}
+				return &object.Float{Value: value}
+			default:
+				return newError("нельзя передавать в в_дробь(), получено %s",
+					args[0].Type())
+			}
+		},
+	},
+
+	// округлить(x) возвращает ближайшее целое число,
+	// округлить(x, n) возвращает дробь с n знаками после точки
+	"округлить": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("неверное количество аргументов получено %d, надо 1 или 2",
+					len(args))
+			}
+			if !isNumber(args[0]) {
+				return newError("первый аргумент должен быть числом или дробью, получено %s",
+					args[0].Type())
+			}
+			value := toFloat(args[0])
+
+			if len(args) == 1 {
+				if isInteger(args[0]) {
+					return args[0]
+				}
+				return floatToInteger(math.Round(value))
+			}
+
+			digits, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("второй аргумент должен быть числом, получено %s",
+					args[1].Type())
+			}
+			scale := math.Pow(10, float64(digits.Value))
+			return &object.Float{Value: math.Round(value*scale) / scale}
+		},
+	},
+
+	// от(a, b) возвращает диапазон a..b, от(a, b, шаг) - диапазон с шагом
+	"от": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("неверное количество аргументов получено %d, надо 2 или 3",
+					len(args))
+			}
+			if len(args) == 2 {
+				return newRange(args[0], args[1], &object.Integer{Value: 1})
+			}
+			return newRange(args[0], args[1], args[2])
+		},
+	},
+
+	"ключи": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("неверное количество аргументов получено %d, надо 1",
+					len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("первый аргумент должен быть словарем, получено %s",
+					args[0].Type())
+			}
+			keys := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Ordered() {
+				keys = append(keys, pair.Key)
+			}
+			return &object.Array{Elements: keys}
+		},
+	},
+
+	"значения": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("неверное количество аргументов получено %d, надо 1",
+					len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("первый аргумент должен быть словарем, получено %s",
+					args[0].Type())
+			}
+			values := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Ordered() {
+				values = append(values, pair.Value)
+			}
+			return &object.Array{Elements: values}
+		},
+	},
+
+	"есть_ключ": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("получено неверное количество аргументов %d, надо 2",
+					len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("первый аргумент должен быть словарем, получено %s",
+					args[0].Type())
+			}
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("нельзя использовать как ключ словаря: %s", args[1].Type())
+			}
+			_, ok = hash.Get(key)
+			return nativeBoolToBooleanObj(ok)
+		},
+	},
+
+	// удалить(словарь, ключ) удаляет пару и возвращает ее значение
+	"удалить": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("получено неверное количество аргументов %d, надо 2",
+					len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("первый аргумент должен быть словарем, получено %s",
+					args[0].Type())
+			}
+			if hash.Frozen {
+				return newError("нельзя изменить постоянный словарь")
+			}
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("нельзя использовать как ключ словаря: %s", args[1].Type())
+			}
+			if value, ok := hash.Delete(key); ok {
+				return value
+			}
+			return NULL
+		},
+	},
+}
+
+// floatToInteger переводит дробь без дробной части в целое число
+func floatToInteger(value float64) object.Object {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return newError("нельзя преобразовать %s в число", (&object.Float{Value: value}).Inspect())
+	}
+	if value < math.MinInt64 || value >= math.MaxInt64 {
+		integer, _ := big.NewFloat(value).Int(nil)
+		return normalizeBigInt(integer)
+	}
+	return &object.Integer{Value: int64(value)}
 }
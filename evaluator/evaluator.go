@@ -2,6 +2,8 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 
 	"github.com/usamaroman/uman/ast"
 	"github.com/usamaroman/uman/object"
@@ -16,10 +18,13 @@ var (
 
 var dataTypes = map[token.TokenType]object.ObjectType{
 	token.INT:      object.IntegerObj,
+	token.BIGINT:   object.BigIntegerObj,
+	token.FLOAT:    object.FloatObj,
 	token.STRING:   object.StringObj,
 	token.BOOL:     object.BooleanObj,
 	token.FUNCTION: object.FunctionObj,
 	token.ARRAY:    object.ArrayObj,
+	token.HASH:     object.HashObj,
 }
 
 func newError(format string, a ...interface{}) *object.Error {
@@ -33,9 +38,20 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// Eval вычисляет узел node. Ошибкам, у которых еще нет позиции,
+// присваивается позиция самого глубокого узла, на котором они возникли
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := eval(node, env)
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
+		foldConstants(node)
 		return evalProgram(node, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
@@ -46,6 +62,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
+		if node.Operator == token.AND || node.Operator == token.OR {
+			return evalLogicalExpression(node, env)
+		}
+
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -54,56 +74,77 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right, env, node.Left.TokenLiteral())
+		return evalInfixExpression(node.Operator, left, right)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.SwitchExpression:
+		return evalSwitchExpression(node, env)
 	case *ast.ForLoopExpression:
 		return evalForLoopExpression(node, env)
+	case *ast.ForEachExpression:
+		return evalForEachExpression(node, env)
 
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.ReturnStatement:
-		val := Eval(node.Value, env)
+		var val object.Object
+		if call, ok := node.Value.(*ast.CallExpression); ok && node.Tail {
+			val = evalTailCall(call, env)
+		} else {
+			val = Eval(node.Value, env)
+		}
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.BreakStatement:
+		return &object.Break{Label: labelName(node.Label)}
+	case *ast.ContinueStatement:
+		return &object.Continue{Label: labelName(node.Label)}
 	case *ast.VariableStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
 
-		if !checkDataType(node, val) {
+		val = convertToDataType(node.DataType, val)
+		if !checkDataType(node.DataType, val) {
 			return newError("неверная инициализация типа данных %s %s", node.DataType, val.Type())
 		}
 
-		if obj, ok := env.Get(node.Ident.Value); ok {
+		if obj, ok := env.GetLocal(node.Ident.Value); ok {
 			return newError("переменная %s уже существует = %s", node.Ident.Value, obj.Inspect())
 		}
 
-		env.Set(node.Ident.Value, val)
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			fn.Name = node.Ident.Value
+		}
+
+		if node.IsConstant() {
+			env.DeclareConst(node.Ident.Value, freeze(val), node.DataType)
+		} else {
+			env.Declare(node.Ident.Value, val, node.DataType)
+		}
+	case *ast.FunctionDeclaration:
+		// объявление уже выполнено в hoistFunctions
+		return nil
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
-		args := node.Arguments
-		body := node.Body
 		return &object.Function{
-			Arguments: args,
-			Body:      body,
-			Env:       env,
+			Arguments:     node.Arguments,
+			ArgumentTypes: node.ArgumentTypes,
+			Defaults:      node.Defaults,
+			Variadic:      node.Variadic,
+			ReturnType:    node.ReturnType,
+			Body:          node.Body,
+			Env:           env,
 		}
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
-			return function
-		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
-		}
-		return applyFunction(function, args)
+		return evalCallExpression(node, env, false)
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -117,7 +158,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// expressions
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInteger{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.BooleanLiteral:
@@ -128,26 +174,193 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
 	default:
 		return nil
 	}
 	return nil
 }
 
-func checkDataType(node *ast.VariableStatement, obj object.Object) bool {
-	val, ok := dataTypes[node.DataType]
+func checkDataType(dataType token.TokenType, obj object.Object) bool {
+	// число и большое_число взаимозаменяемы: число, не помещающееся
+	// в int64, автоматически становится большим и наоборот
+	if isInteger(obj) && (dataType == token.INT || dataType == token.BIGINT) {
+		return true
+	}
+
+	val, ok := dataTypes[dataType]
 	if !ok {
 		return false
 	}
 	return val == obj.Type()
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// convertToDataType приводит целое число к дроби, если переменная объявлена как дробь
+func convertToDataType(dataType token.TokenType, obj object.Object) object.Object {
+	if isInteger(obj) && dataType == token.FLOAT {
+		return &object.Float{Value: toFloat(obj)}
+	}
+	return obj
+}
+
+// typeNames - названия типов значений так, как они пишутся в программе
+var typeNames = map[object.ObjectType]string{
+	object.IntegerObj:    "число",
+	object.BigIntegerObj: "большое_число",
+	object.FloatObj:      "дробь",
+	object.StringObj:     "строка",
+	object.BooleanObj:    "булев",
+	object.FunctionObj:   "функция",
+	object.BuiltinObj:    "функция",
+	object.ArrayObj:      "массив",
+	object.HashObj:       "словарь",
+	object.RangeObj:      "диапазон",
+	object.NullObj:       "пусто",
+}
+
+func typeName(t object.ObjectType) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return string(t)
+}
+
+func dataTypeName(dataType token.TokenType) string {
+	return typeName(dataTypes[dataType])
+}
+
+// VariableType возвращает тип переменной name: объявленный при создании,
+// а если его нет, то тип текущего значения
+func VariableType(env *object.Environment, name string) (string, bool) {
+	if dataType, ok := env.DataType(name); ok {
+		return dataTypeName(dataType), true
+	}
+	if obj, ok := env.Get(name); ok {
+		return typeName(obj.Type()), true
+	}
+	return "", false
+}
+
+// evalCallExpression вычисляет вызов функции. Если tail, вызов стоит в хвостовой
+// инструкции вернуть (ast.ReturnStatement.Tail), и функция вызывает саму себя,
+// возвращается *object.TailCall
+func evalCallExpression(node *ast.CallExpression, env *object.Environment, tail bool) object.Object {
+	function := Eval(node.Function, env)
+	if isError(function) {
+		return function
+	}
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	// вызов с теми же аргументами выполняется как обычный: без изменений
+	// снаружи функции такая рекурсия бесконечна и должна упереться в предел
+	// глубины, а не зациклиться
+	if frame := env.Frame(); tail && frame != nil && frame.Fn == function && !sameArgs(frame.Args, args) {
+		return &object.TailCall{Args: args, Pos: node.Pos()}
+	}
+
+	return applyFunction(function, args, node.Pos(), env)
+}
+
+// sameArgs сообщает, совпадают ли args с аргументами вызова frameArgs.
+// Сравниваются только неизменяемые значения: массивы и словари в стеке
+// вызовов хранятся краткой записью и всегда считаются разными
+func sameArgs(frameArgs, args []object.Object) bool {
+	if len(frameArgs) != len(args) {
+		return false
+	}
+
+	for i, arg := range args {
+		switch prev := frameArgs[i].(type) {
+		case *object.Integer:
+			if arg, ok := arg.(*object.Integer); !ok || arg.Value != prev.Value {
+				return false
+			}
+		case *object.BigInteger:
+			if arg, ok := arg.(*object.BigInteger); !ok || arg.Value.Cmp(prev.Value) != 0 {
+				return false
+			}
+		case *object.Float:
+			if arg, ok := arg.(*object.Float); !ok || arg.Value != prev.Value {
+				return false
+			}
+		case *object.Boolean:
+			if arg, ok := arg.(*object.Boolean); !ok || arg.Value != prev.Value {
+				return false
+			}
+		case *object.String:
+			if arg, ok := arg.(*object.String); !ok || arg.Value != prev.Value {
+				return false
+			}
+		case *object.Null, *object.Function:
+			if arg != prev {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// evalTailCall вычисляет вызов в инструкции вернуть так же, как Eval,
+// но позволяет функции вызвать саму себя без роста стека
+func evalTailCall(node *ast.CallExpression, env *object.Environment) object.Object {
+	result := evalCallExpression(node, env, true)
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	return result
+}
+
+// applyFunction вызывает fn с аргументами args. pos - место вызова, env -
+// область видимости вызывающего кода, из нее берется стек вызовов
+func applyFunction(fn object.Object, args []object.Object, pos token.Position, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		frame := newFrame(fn, args, pos, env.Frame())
+		if limit := maxCallDepth(env); frame.Depth > limit {
+			err := newError("слишком глубокая рекурсия: больше %d вызовов", limit)
+			err.Trace = frame.Caller.Stack(maxTraceFrames)
+			return err
+		}
+
+		for {
+			extendedEnv, err := extendFunctionEnv(fn, args, frame)
+			if err != nil {
+				if !err.Pos.IsValid() {
+					err.Pos = frame.Pos
+				}
+				return err
+			}
+			evaluated := unwrapReturnValue(evalStatements(fn.Body, extendedEnv))
+			if evaluated == nil {
+				// пустое тело функции возвращает пусто
+				evaluated = NULL
+			}
+
+			// вызов самой себя в конце функции заменяет текущий вызов,
+			// поэтому стек не растет
+			if call, ok := evaluated.(*object.TailCall); ok {
+				tailCalls := frame.TailCalls + 1
+				args = call.Args
+				frame = newFrame(fn, args, call.Pos, frame.Caller)
+				frame.TailCalls = tailCalls
+				continue
+			}
+
+			if err, ok := evaluated.(*object.Error); ok {
+				// стек записывает самый глубокий вызов, в котором возникла ошибка
+				if err.Trace == nil {
+					err.Trace = frame.Stack(maxTraceFrames)
+				}
+				return err
+			}
+			return checkReturnType(fn, evaluated)
+		}
 	case *object.Builtin:
 		return fn.Fn(args...)
 	default:
@@ -155,14 +368,150 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// extendFunctionEnv связывает аргументы функции с переданными значениями:
+// недостающие аргументы получают значения по умолчанию, лишние собираются
+// в массив последним аргументом с ... . Типы проверяются, если они указаны
+func extendFunctionEnv(fn *object.Function, args []object.Object, frame *object.Frame) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(fn.Env)
+	env.SetFrame(frame)
+
+	if err := checkArity(fn, len(args)); err != nil {
+		return nil, err
+	}
 
 	for paramIdx, param := range fn.Arguments {
-		env.Set(param.Value, args[paramIdx])
+		var dataType token.TokenType
+		if paramIdx < len(fn.ArgumentTypes) {
+			dataType = fn.ArgumentTypes[paramIdx]
+		}
+
+		if fn.Variadic && paramIdx == len(fn.Arguments)-1 {
+			rest := make([]object.Object, 0)
+			for _, arg := range args[min(paramIdx, len(args)):] {
+				if dataType != "" {
+					arg = convertToDataType(dataType, arg)
+					if !checkDataType(dataType, arg) {
+						return nil, newError("%s: элементы %s должны иметь тип %s, получено %s",
+							functionName(fn), param.Value, dataTypeName(dataType), typeName(arg.Type()))
+					}
+				}
+				rest = append(rest, arg)
+			}
+			env.Declare(param.Value, &object.Array{Elements: rest}, token.ARRAY)
+			continue
+		}
+
+		var arg object.Object
+		if paramIdx < len(args) {
+			arg = args[paramIdx]
+		} else {
+			// значение по умолчанию вычисляется при каждом вызове
+			// и может использовать предыдущие аргументы
+			arg = Eval(fn.Defaults[paramIdx], env)
+			if err, ok := arg.(*object.Error); ok {
+				return nil, err
+			}
+		}
+
+		if dataType == "" {
+			env.Set(param.Value, arg)
+			continue
+		}
+
+		arg = convertToDataType(dataType, arg)
+		if !checkDataType(dataType, arg) {
+			return nil, newError("%s: аргумент %s должен иметь тип %s, получено %s",
+				functionName(fn), param.Value, dataTypeName(dataType), typeName(arg.Type()))
+		}
+		env.Declare(param.Value, arg, dataType)
 	}
 
-	return env
+	return env, nil
+}
+
+// DefaultMaxCallDepth - наибольшее количество вложенных вызовов функций, если
+// предел не задан через Environment.SetMaxCallDepth. Более глубокая рекурсия
+// останавливает программу с ошибкой, а не переполняет стек Go
+const DefaultMaxCallDepth = 10000
+
+func maxCallDepth(env *object.Environment) int {
+	if depth := env.MaxCallDepth(); depth > 0 {
+		return depth
+	}
+	return DefaultMaxCallDepth
+}
+
+// maxTraceFrames - сколько последних вызовов записывается в ошибку
+const maxTraceFrames = 20
+
+// newFrame создает запись о вызове fn для стека вызовов
+func newFrame(fn *object.Function, args []object.Object, pos token.Position, caller *object.Frame) *object.Frame {
+	name := fn.Name
+	if name == "" {
+		name = "функция"
+	}
+
+	depth := 1
+	if caller != nil {
+		depth = caller.Depth + 1
+	}
+
+	return &object.Frame{Fn: fn, Function: name, Pos: pos, Args: object.SnapshotArgs(args), Caller: caller, Depth: depth}
+}
+
+// checkArity проверяет количество переданных функции аргументов
+func checkArity(fn *object.Function, count int) *object.Error {
+	required := 0
+	for i := range fn.Arguments {
+		if fn.Variadic && i == len(fn.Arguments)-1 {
+			continue
+		}
+		if i >= len(fn.Defaults) || fn.Defaults[i] == nil {
+			required++
+		}
+	}
+
+	allowed := len(fn.Arguments)
+	if fn.Variadic {
+		allowed--
+	}
+
+	switch {
+	case fn.Variadic && count < required:
+		return newError("%s: неверное количество аргументов получено %d, надо не меньше %d",
+			functionName(fn), count, required)
+	case fn.Variadic:
+		return nil
+	case count < required || count > allowed:
+		if required == allowed {
+			return newError("%s: неверное количество аргументов получено %d, надо %d",
+				functionName(fn), count, required)
+		}
+		return newError("%s: неверное количество аргументов получено %d, надо от %d до %d",
+			functionName(fn), count, required, allowed)
+	}
+	return nil
+}
+
+// checkReturnType проверяет тип результата функции, если он указан
+func checkReturnType(fn *object.Function, result object.Object) object.Object {
+	if fn.ReturnType == "" {
+		return result
+	}
+
+	result = convertToDataType(fn.ReturnType, result)
+	if !checkDataType(fn.ReturnType, result) {
+		return newError("%s должна вернуть %s, получено %s",
+			functionName(fn), dataTypeName(fn.ReturnType), typeName(result.Type()))
+	}
+	return result
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "функция"
+	}
+	return "функция " + fn.Name
 }
 func unwrapReturnValue(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
@@ -204,6 +553,8 @@ func evalIfExpression(node *ast.IfExpression, env *object.Environment) object.Ob
 	}
 	if isTrue(condition) {
 		return Eval(node.Consequence, env)
+	} else if node.ElseIf != nil {
+		return Eval(node.ElseIf, env)
 	} else if node.Alternative != nil {
 		return Eval(node.Alternative, env)
 	} else {
@@ -211,23 +562,384 @@ func evalIfExpression(node *ast.IfExpression, env *object.Environment) object.Ob
 	}
 }
 
+// evalSwitchExpression выполняет первую ветку, одно из значений которой
+// равно выражению выбора по правилам оператора ==
+func evalSwitchExpression(node *ast.SwitchExpression, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, c := range node.Cases {
+		for _, valueNode := range c.Values {
+			value := Eval(valueNode, env)
+			if isError(value) {
+				return value
+			}
+
+			equal := evalInfixExpression(token.EQUALS, subject, value)
+			if isError(equal) {
+				return equal
+			}
+			if isTrue(equal) {
+				return evalSwitchBody(c.Body, env)
+			}
+		}
+	}
+
+	if node.Default != nil {
+		return evalSwitchBody(node.Default, env)
+	}
+
+	return NULL
+}
+
+// evalSwitchBody выполняет ветку выбора. Пустая ветка возвращает пусто
+func evalSwitchBody(body *ast.BlockStatement, env *object.Environment) object.Object {
+	if result := Eval(body, env); result != nil {
+		return result
+	}
+	return NULL
+}
+
 func evalForLoopExpression(node *ast.ForLoopExpression, env *object.Environment) object.Object {
-	condition := Eval(node.Condition, env)
-	if isError(condition) {
-		return condition
+	label := labelName(node.Label)
+
+	// переменные из первой части цикла видны только внутри цикла
+	if node.Init != nil {
+		env = object.NewEnclosedEnvironment(env)
+		if init := Eval(node.Init, env); isError(init) {
+			return init
+		}
+	}
+
+	for {
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if condition.Type() != object.BooleanObj {
+			return newError("условие должно быть булевого типа, получено %s", condition.Type())
+		}
+		if !isTrue(condition) {
+			return NULL
+		}
+
+		if result, done := loopControl(Eval(node.Statement, env), label); done {
+			return result
+		}
+
+		if node.Post != nil {
+			if post := Eval(node.Post, env); isError(post) {
+				return post
+			}
+		}
 	}
+}
 
-	if condition.Type() != object.BooleanObj {
-		return newError("условие должно быть булевого типа, получено %s", condition.Type())
+// evalForEachExpression выполняет тело цикла для каждого элемента коллекции.
+// Переменные цикла видны только внутри тела
+func evalForEachExpression(node *ast.ForEachExpression, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
 	}
 
-	for isTrue(Eval(node.Condition, env)) {
-		Eval(node.Statement, env)
+	label := labelName(node.Label)
+	iterate := func(index, value object.Object) (object.Object, bool) {
+		loopEnv := object.NewEnclosedEnvironment(env)
+		if node.Index != nil {
+			loopEnv.Set(node.Index.Value, index)
+		}
+		loopEnv.Set(node.Value.Value, value)
+
+		return loopControl(Eval(node.Statement, loopEnv), label)
+	}
+
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for i, element := range iterable.Elements {
+			if result, done := iterate(&object.Integer{Value: int64(i)}, element); done {
+				return result
+			}
+		}
+	case *object.String:
+		i := int64(0)
+		for _, r := range iterable.Value {
+			if result, done := iterate(&object.Integer{Value: i}, &object.String{Value: string(r)}); done {
+				return result
+			}
+			i++
+		}
+	case *object.Hash:
+		for _, pair := range iterable.Ordered() {
+			value := pair.Value
+			if node.Index == nil {
+				value = pair.Key
+			}
+			if result, done := iterate(pair.Key, value); done {
+				return result
+			}
+		}
+	case *object.Range:
+		i := int64(0)
+		for n := iterable.Start; inRange(n, iterable); n += iterable.Step {
+			if result, done := iterate(&object.Integer{Value: i}, &object.Integer{Value: n}); done {
+				return result
+			}
+			i++
+			if (iterable.Step > 0 && n > math.MaxInt64-iterable.Step) ||
+				(iterable.Step < 0 && n < math.MinInt64-iterable.Step) {
+				break
+			}
+		}
+	default:
+		return newError("нельзя перебрать %s", iterable.Type())
 	}
 
 	return NULL
 }
 
+func inRange(n int64, r *object.Range) bool {
+	if r.Step > 0 {
+		return n <= r.End
+	}
+	return n >= r.End
+}
+
+// loopControl разбирает результат выполнения тела цикла. done означает,
+// что цикл надо завершить и вернуть result
+func loopControl(result object.Object, label string) (object.Object, bool) {
+	switch result := result.(type) {
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	case *object.Break:
+		if result.Label != "" && result.Label != label {
+			return result, true
+		}
+		return NULL, true
+	case *object.Continue:
+		if result.Label != "" && result.Label != label {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// newRange создает диапазон от start до end включительно
+func newRange(start, end, step object.Object) object.Object {
+	from, ok1 := start.(*object.Integer)
+	to, ok2 := end.(*object.Integer)
+	if !ok1 || !ok2 {
+		return newError("границы диапазона должны быть числами, получено %s и %s",
+			start.Type(), end.Type())
+	}
+	by, ok := step.(*object.Integer)
+	if !ok {
+		return newError("шаг диапазона должен быть числом, получено %s", step.Type())
+	}
+	if by.Value == 0 {
+		return newError("шаг диапазона не может быть нулем")
+	}
+	return &object.Range{Start: from.Value, End: to.Value, Step: by.Value}
+}
+
+func labelName(label *ast.Identifier) string {
+	if label == nil {
+		return ""
+	}
+	return label.Value
+}
+
+// evalLogicalExpression вычисляет && и ||. Правая часть вычисляется,
+// только если левой части недостаточно для ответа
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	if left.Type() != object.BooleanObj {
+		return newError("оператор %s применим только к булевым значениям, получено %s",
+			node.Operator, left.Type())
+	}
+
+	if node.Operator == token.AND && !isTrue(left) {
+		return FALSE
+	}
+	if node.Operator == token.OR && isTrue(left) {
+		return TRUE
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+	if right.Type() != object.BooleanObj {
+		return newError("оператор %s применим только к булевым значениям, получено %s",
+			node.Operator, right.Type())
+	}
+
+	return right
+}
+
+// assignOperators сопоставляет присваиванию арифметический оператор,
+// которым новое значение вычисляется из старого
+var assignOperators = map[string]string{
+	token.ASSIGN:          "",
+	token.PLUS_ASSIGN:     token.PLUS,
+	token.MINUS_ASSIGN:    token.MINUS,
+	token.ASTERISK_ASSIGN: token.ASTERISK,
+	token.SLASH_ASSIGN:    token.SLASH,
+	token.PERCENT_ASSIGN:  token.PERCENT,
+}
+
+// evalAssignExpression вычисляет x = y, x += y, мас[i] = y и мас[i] += y
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	operator := assignOperators[node.Operator]
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		current, ok := env.Get(target.Value)
+		if !ok {
+			return newError("нет переменной: %s", target.Value)
+		}
+		if env.IsConst(target.Value) {
+			return newError("нельзя изменить постоянную %s", target.Value)
+		}
+		value := evalAssignedValue(current, operator, node.Value, env)
+		if isError(value) {
+			return value
+		}
+		value = checkAssignment(env, target.Value, value)
+		if isError(value) {
+			return value
+		}
+		env.Assign(target.Value, value)
+		return value
+	case *ast.IndexExpression:
+		return evalIndexAssignment(target, operator, node.Value, env)
+	default:
+		return newError("нельзя присваивать значение выражению %s", node.Target.String())
+	}
+}
+
+// freeze возвращает неизменяемую копию массива или словаря для постоянной.
+// Значение копируется, чтобы переменная, из которой оно взято, осталась изменяемой
+func freeze(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Array:
+		elements := make([]object.Object, len(obj.Elements))
+		for i, element := range obj.Elements {
+			elements[i] = freeze(element)
+		}
+		return &object.Array{Elements: elements, Frozen: true}
+	case *object.Hash:
+		hash := object.NewHash()
+		for _, pair := range obj.Ordered() {
+			hash.Set(pair.Key, freeze(pair.Value))
+		}
+		hash.Frozen = true
+		return hash
+	default:
+		return obj
+	}
+}
+
+// checkAssignment проверяет, что value можно записать в переменную name
+// с объявленным типом, и приводит целое число к дроби. Переменным без
+// объявленного типа, например аргументам функций, можно присвоить что угодно
+func checkAssignment(env *object.Environment, name string, value object.Object) object.Object {
+	dataType, ok := env.DataType(name)
+	if !ok {
+		return value
+	}
+
+	value = convertToDataType(dataType, value)
+	if !checkDataType(dataType, value) {
+		return newError("нельзя присвоить значение типа %s переменной %s типа %s",
+			typeName(value.Type()), name, dataTypeName(dataType))
+	}
+	return value
+}
+
+// frozenError сообщает о попытке изменить элемент постоянной. В сообщении
+// указывается имя постоянной, а не все выражение вида М[0][1]
+func frozenError(target *ast.IndexExpression) *object.Error {
+	var exp ast.Expression = target
+	for {
+		switch node := exp.(type) {
+		case *ast.IndexExpression:
+			exp = node.Left
+		case *ast.Identifier:
+			return newError("нельзя изменить постоянную %s", node.Value)
+		default:
+			return newError("нельзя изменить постоянное значение")
+		}
+	}
+}
+
+// evalIndexAssignment записывает значение в элемент массива или словаря.
+// Пустой operator означает обычное присваивание, иначе новое значение
+// получается применением operator к старому значению и правой части
+func evalIndexAssignment(target *ast.IndexExpression, operator string, valueNode ast.Expression, env *object.Environment) object.Object {
+	left := Eval(target.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := Eval(target.Index, env)
+	if isError(index) {
+		return index
+	}
+
+	switch container := left.(type) {
+	case *object.Array:
+		if container.Frozen {
+			return frozenError(target)
+		}
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("индекс массива должен быть числом, получено %s", index.Type())
+		}
+		if idx.Value < 0 || idx.Value >= int64(len(container.Elements)) {
+			return newError("индекс %d вне границ массива длины %d", idx.Value, len(container.Elements))
+		}
+		value := evalAssignedValue(container.Elements[idx.Value], operator, valueNode, env)
+		if isError(value) {
+			return value
+		}
+		container.Elements[idx.Value] = value
+		return value
+	case *object.Hash:
+		if container.Frozen {
+			return frozenError(target)
+		}
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("нельзя использовать как ключ словаря: %s", index.Type())
+		}
+		current, ok := container.Get(key)
+		if !ok && operator != "" {
+			return newError("нет ключа: %s", index.Inspect())
+		}
+		value := evalAssignedValue(current, operator, valueNode, env)
+		if isError(value) {
+			return value
+		}
+		container.Set(key, value)
+		return value
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+func evalAssignedValue(current object.Object, operator string, valueNode ast.Expression, env *object.Environment) object.Object {
+	right := Eval(valueNode, env)
+	if isError(right) || operator == "" {
+		return right
+	}
+	return evalInfixExpression(operator, current, right)
+}
+
 func isTrue(obj object.Object) bool {
 	switch obj {
 	case TRUE:
@@ -241,12 +953,16 @@ func isTrue(obj object.Object) bool {
 	}
 }
 
-func evalInfixExpression(operator string, left object.Object, right object.Object, env *object.Environment, ident string) object.Object {
+func evalInfixExpression(operator string, left object.Object, right object.Object) object.Object {
 	switch {
-	case operator == "=":
-		return env.Set(ident, right)
+	case operator == token.DOTDOT:
+		return newRange(left, right, &object.Integer{Value: 1})
 	case left.Type() == object.IntegerObj && right.Type() == object.IntegerObj:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.StringObj && right.Type() == object.StringObj:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -261,14 +977,19 @@ func evalInfixExpression(operator string, left object.Object, right object.Objec
 }
 
 func evalStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
-	if operator != "+" {
-		return newError("неизвестный оператор: %s %s %s", left.Type(), operator, right.Type())
-	}
-
 	leftValue := left.(*object.String).Value
 	rightValue := right.(*object.String).Value
 
-	return &object.String{Value: leftValue + rightValue}
+	switch operator {
+	case "+":
+		return &object.String{Value: leftValue + rightValue}
+	case "==":
+		return nativeBoolToBooleanObj(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObj(leftValue != rightValue)
+	default:
+		return newError("неизвестный оператор: %s %s %s", left.Type(), operator, right.Type())
+	}
 }
 
 func evalIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
@@ -277,13 +998,44 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		if value, ok := addInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: value}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		if value, ok := subInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: value}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		if rightVal == 0 {
+			return newError("деление на ноль")
+		}
+		if value, ok := divInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: value}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		if value, ok := mulInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: value}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "%":
+		if rightVal == 0 {
+			return newError("деление на ноль")
+		}
+		if rightVal == -1 {
+			return &object.Integer{Value: 0}
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		if rightVal < 0 {
+			return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+		if value, ok := powInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: value}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case ">":
 		return nativeBoolToBooleanObj(leftVal > rightVal)
 	case "<":
@@ -301,6 +1053,80 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	}
 }
 
+// evalFloatInfixExpression вычисляет выражение, в котором хотя бы один
+// операнд дробь, целый операнд при этом приводится к дроби
+func evalFloatInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("деление на ноль")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("деление на ноль")
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case ">":
+		return nativeBoolToBooleanObj(leftVal > rightVal)
+	case "<":
+		return nativeBoolToBooleanObj(leftVal < rightVal)
+	case ">=":
+		return nativeBoolToBooleanObj(leftVal >= rightVal)
+	case "<=":
+		return nativeBoolToBooleanObj(leftVal <= rightVal)
+	case "==":
+		return nativeBoolToBooleanObj(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObj(leftVal != rightVal)
+	default:
+		return newError("неизвестный оператор: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	switch obj.Type() {
+	case object.IntegerObj, object.BigIntegerObj, object.FloatObj:
+		return true
+	default:
+		return false
+	}
+}
+
+func isInteger(obj object.Object) bool {
+	switch obj.Type() {
+	case object.IntegerObj, object.BigIntegerObj:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
@@ -326,12 +1152,19 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.IntegerObj {
+	switch right := right.(type) {
+	case *object.Integer:
+		if value, ok := negInt64(right.Value); ok {
+			return &object.Integer{Value: value}
+		}
+		return normalizeBigInt(new(big.Int).Neg(big.NewInt(right.Value)))
+	case *object.BigInteger:
+		return normalizeBigInt(new(big.Int).Neg(right.Value))
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("неизвестный оператор: -%s", right.Type())
 	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func nativeBoolToBooleanObj(value bool) *object.Boolean {
@@ -344,6 +1177,10 @@ func nativeBoolToBooleanObj(value bool) *object.Boolean {
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
 
+	if err := hoistFunctions(program.Statements, env); err != nil {
+		return err
+	}
+
 	for _, statement := range program.Statements {
 		result = Eval(statement, env)
 
@@ -352,21 +1189,42 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break:
+			return newError("прервать вне цикла")
+		case *object.Continue:
+			return newError("продолжить вне цикла")
 		}
 	}
 
 	return result
 }
 
+// evalBlockStatement выполняет блок в собственной области видимости:
+// переменные, созданные в блоке, не видны снаружи
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	// блоку без объявлений своя область видимости не нужна
+	if declaresNames(block) {
+		env = object.NewEnclosedEnvironment(env)
+	}
+	return evalStatements(block, env)
+}
+
+// evalStatements выполняет инструкции блока прямо в env. Так выполняется тело
+// функции: его переменные создаются рядом с аргументами и не могут их скрыть
+func evalStatements(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if err := hoistFunctions(block.Statements, env); err != nil {
+		return err
+	}
+
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.ReturnValueObj || rt == object.ErrorObj {
+			if rt == object.ReturnValueObj || rt == object.ErrorObj ||
+				rt == object.BreakObj || rt == object.ContinueObj {
 				return result
 			}
 		}
@@ -375,10 +1233,92 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	return result
 }
 
+// declaresNames сообщает, создает ли блок переменные или функции
+func declaresNames(block *ast.BlockStatement) bool {
+	for _, statement := range block.Statements {
+		switch statement.(type) {
+		case *ast.VariableStatement, *ast.FunctionDeclaration:
+			return true
+		}
+	}
+	return false
+}
+
+// hoistFunctions объявляет функции из statements до выполнения остальных
+// инструкций, поэтому функцию можно вызвать выше ее объявления
+func hoistFunctions(statements []ast.Statement, env *object.Environment) *object.Error {
+	for _, statement := range statements {
+		decl, ok := statement.(*ast.FunctionDeclaration)
+		if !ok {
+			continue
+		}
+
+		// переменная с тем же именем в этом блоке тоже конфликтует: функция
+		// объявляется раньше нее
+		_, exists := env.GetLocal(decl.Name.Value)
+		if exists || declaresVariable(statements, decl.Name.Value) {
+			err := newError("переменная %s уже существует", decl.Name.Value)
+			err.Pos = decl.Name.Pos()
+			return err
+		}
+
+		f := decl.Function
+		env.Declare(decl.Name.Value, &object.Function{
+			Name:          decl.Name.Value,
+			Arguments:     f.Arguments,
+			ArgumentTypes: f.ArgumentTypes,
+			Defaults:      f.Defaults,
+			Variadic:      f.Variadic,
+			ReturnType:    f.ReturnType,
+			Body:          f.Body,
+			Env:           env,
+		}, token.FUNCTION)
+	}
+
+	return nil
+}
+
+// declaresVariable сообщает, создается ли среди statements переменная name
+func declaresVariable(statements []ast.Statement, name string) bool {
+	for _, statement := range statements {
+		if stmt, ok := statement.(*ast.VariableStatement); ok && stmt.Ident.Value == name {
+			return true
+		}
+	}
+	return false
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("нельзя использовать как ключ словаря: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash
+}
+
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ArrayObj && index.Type() == object.IntegerObj:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.HashObj:
+		return evalHashIndexExpression(left, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -394,3 +1334,17 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 
 	return arrayObject.Elements[idx]
 }
+
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("нельзя использовать как ключ словаря: %s", index.Type())
+	}
+
+	value, ok := hash.(*object.Hash).Get(key)
+	if !ok {
+		return NULL
+	}
+
+	return value
+}
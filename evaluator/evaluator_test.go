@@ -1,6 +1,8 @@
 package evaluator
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/usamaroman/uman/object"
@@ -14,6 +16,14 @@ func testEval(input string) object.Object {
 	return Eval(program, env)
 }
 
+func testEvalWithDepth(input string, maxCallDepth int) object.Object {
+	p := parser.New(input)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+	env.SetMaxCallDepth(maxCallDepth)
+	return Eval(program, env)
+}
+
 func TestEvalIntegerExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -55,6 +65,43 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+// errMsg - ожидаемое сообщение об ошибке в таблицах тестов, чтобы отличать
+// его от ожидаемой строки
+type errMsg string
+
+// testObject проверяет результат вычисления: число, дробь, булево значение,
+// строку или ошибку с сообщением errMsg. nil означает пусто
+func testObject(t *testing.T, obj object.Object, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, obj, int64(expected))
+	case float64:
+		return testFloatObject(t, obj, expected)
+	case bool:
+		return testBooleanObject(t, obj, expected)
+	case errMsg:
+		return testErrorObject(t, obj, string(expected))
+	case string:
+		return testStringObject(t, obj, expected)
+	default:
+		return testNullObject(t, obj)
+	}
+}
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q",
+			expected, errObj.Message)
+		return false
+	}
+	return true
+}
+
 func TestEvalStringExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -475,3 +522,1154 @@ func TestArrayIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"тест", "1:1"},
+		{"создать x: число = 1;\nx + истина;", "2:3"},
+		{"1;\n  -истина;", "2:3"},
+		{"создать ф: функция = функция(x) {\n\tвернуть x + y;\n};\nф(1);", "2:14"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Pos.String() != tt.expected {
+			t.Errorf("wrong error position. expected=%q, got=%q",
+				tt.expected, errObj.Pos.String())
+		}
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"2.0 * (1 + 1.5)", 5},
+		{"1e3 - 1", 999},
+		{"создать r: дробь = 2; r * r * 3.14", 12.56},
+		{"в_дробь(3)", 3},
+		{`в_дробь(" 2.5 ")`, 2.5},
+		{"округлить(3.14159, 2)", 3.14},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if math.Abs(result.Value-expected) > 1e-9 {
+		t.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func TestFloatConversions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"в_число(3.99)", 3},
+		{"в_число(-3.99)", -3},
+		{`в_число("42")`, 42},
+		{"в_число(7)", 7},
+		{"округлить(2.5)", 3},
+		{"округлить(-2.4)", -2},
+		{"округлить(5)", 5},
+		{"1.0 == 1", true},
+		{"0.1 + 0.2 > 0.3", true},
+		{"2.5 <= 2", false},
+		{`в_число("abc")`, errMsg(`нельзя преобразовать "abc" в число`)},
+		{`в_дробь(истина)`, errMsg("нельзя передавать в в_дробь(), получено BOOLEAN")},
+		{"1.5 + истина", errMsg("разные типы: FLOAT + BOOLEAN")},
+		{"создать x: число = 1.5;", errMsg("неверная инициализация типа данных INT FLOAT")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2.0", "2.0"},
+		{"3.14", "3.14"},
+		{"1 / 4.0", "0.25"},
+		{"1e21", "1e+21"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect. expected=%q, got=%q", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"истина && истина", true},
+		{"истина && ложь", false},
+		{"ложь || истина", true},
+		{"ложь || ложь", false},
+		{"1 < 2 и 2 < 3", true},
+		{"1 > 2 или 2 > 3", false},
+		{"не истина", false},
+		{"не (1 > 2) и истина", true},
+		{"ложь && (1 + истина)", false},
+		{"истина || нет_такой", true},
+		{"ложь и вывести(1)", false},
+		{"1 && истина", errMsg("оператор && применим только к булевым значениям, получено INTEGER")},
+		{"ложь или 1", errMsg("оператор || применим только к булевым значениям, получено INTEGER")},
+		{"истина && (1 + истина)", errMsg("разные типы: INTEGER + BOOLEAN")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestModuloAndPower(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"10 % 3", 1},
+		{"10 % 2 == 0", true},
+		{"-7 % 3", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 0.5 * 2.0 ** 0.5", 2.0},
+		{"7.5 % 2", 1.5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать x: число = 5; x += 2; x;", 7},
+		{"создать x: число = 5; x -= 2; x;", 3},
+		{"создать x: число = 5; x *= 2; x;", 10},
+		{"создать x: число = 5; x /= 2; x;", 2},
+		{"создать x: число = 5; x %= 2; x;", 1},
+		{"создать x: число = 5; x += 2 * 3;", 11},
+		{`создать s: строка = "при"; s += "вет"; s;`, "привет"},
+		{"создать мас: массив = [1, 2, 3]; мас[1] += 10; мас[1];", 12},
+		{"создать мас: массив = [1, 2, 3]; создать i: число = 2; мас[i] *= мас[i]; мас[2];", 9},
+		{"создать мас: массив = [1, 2, 3]; мас[3] += 1;", errMsg("индекс 3 вне границ массива длины 3")},
+		{"y += 1;", errMsg("нет переменной: y")},
+		{"создать x: число = 1; x += y;", errMsg("нет переменной: y")},
+		{`создать x: число = 5; x += "a";`, errMsg("разные типы: INTEGER + STRING")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать x: число = 1; x = 5;", 5},
+		{"создать a: число = 1; создать b: число = 2; a = b = 7; a + b;", 14},
+		{"создать мас: массив = [1, 2, 3]; мас[0] = 5; мас[0] + мас[1];", 7},
+		{"создать мас: массив = [1, 2, 3]; создать копия: массив = мас; копия[2] = 10; мас[2];", 10},
+		{"создать мас: массив = [[1, 2], [3, 4]]; мас[1][0] = 30; мас[1][0];", 30},
+		{"создать мас: массив = [1, 2, 3]; мас[-1] = 5;", errMsg("индекс -1 вне границ массива длины 3")},
+		{`создать мас: массив = [1, 2, 3]; мас["0"] = 5;`, errMsg("индекс массива должен быть числом, получено STRING")},
+		{"y = 1;", errMsg("нет переменной: y")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBreakAndContinue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`
+создать i: число = 0;
+цикл (истина) {
+    i += 1;
+    если (i == 5) { прервать; }
+}
+i;`, 5},
+		{`
+создать i: число = 0;
+создать сумма: число = 0;
+цикл (i < 10) {
+    i += 1;
+    если (i % 2 == 0) { продолжить; }
+    сумма += i;
+}
+сумма;`, 25},
+		{`
+создать i: число = 0;
+создать j: число = 0;
+создать n: число = 0;
+внешний: цикл (i < 3) {
+    i += 1;
+    j = 0;
+    цикл (истина) {
+        j += 1;
+        n += 1;
+        если (j == 2) { продолжить внешний; }
+    }
+}
+n;`, 6},
+		{`
+создать n: число = 0;
+внешний: цикл (истина) {
+    цикл (истина) {
+        n += 1;
+        прервать внешний;
+    }
+    n += 100;
+}
+n;`, 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestLoopPropagation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`
+создать найти: функция = функция(мас, x) {
+    создать i: число = 0;
+    цикл (i < длина(мас)) {
+        если (мас[i] == x) { вернуть i; }
+        i += 1;
+    }
+    вернуть -1;
+};
+найти([5, 6, 7], 6);`, 1},
+		{`
+создать i: число = 0;
+цикл (i < 3) {
+    i += 1;
+    если (i == 2) { i + истина; }
+}`, errMsg("разные типы: INTEGER + BOOLEAN")},
+		{`
+создать i: число = 0;
+цикл (i < 3) {
+    i += 1;
+    вывести(y);
+}`, errMsg("нет переменной: y")},
+		{`
+создать x: массив = [истина, истина, 1];
+создать i: число = 0;
+цикл (x[i]) { i += 1; }`, errMsg("условие должно быть булевого типа, получено INTEGER")},
+		{`
+создать i: число = 2;
+цикл (10 / i > 0) { i -= 1; }`, errMsg("деление на ноль")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать с: число = 0; цикл (x в [1, 2, 3]) { с += x; } с;", 6},
+		{"создать с: число = 0; цикл (i, x в [10, 20, 30]) { с += i * x; } с;", 80},
+		{`создать с: строка = ""; цикл (б в "мир") { с = б + с; } с;`, "рим"},
+		{`создать с: число = 0; цикл (i, б в "мир") { с += i; } с;`, 3},
+		{`создать с: строка = ""; цикл (к в {"а": 1, "б": 2}) { с += к; } с;`, "аб"},
+		{`создать с: число = 0; цикл (к, з в {"а": 1, "б": 2}) { с += з; } с;`, 3},
+		{"создать с: число = 0; цикл (n в 1..10) { с += n; } с;", 55},
+		{"создать с: число = 0; цикл (n в 5..1) { с += n; } с;", 0},
+		{"создать с: число = 0; цикл (n в от(0, 10, 3)) { с += n; } с;", 18},
+		{"создать с: число = 0; цикл (n в от(3, 1, -1)) { с += n; } с;", 6},
+		{"создать с: число = 0; цикл (n в 1..10) { если (n == 4) { прервать; } с += n; } с;", 6},
+		{"создать с: число = 0; цикл (n в 1..5) { если (n % 2 == 0) { продолжить; } с += n; } с;", 9},
+		{"цикл (x в [1, 2]) { создать y: число = x; } x;", errMsg("нет переменной: x")},
+		{"цикл (x в 5) { }", errMsg("нельзя перебрать INTEGER")},
+		{"цикл (x в 1..истина) { }", errMsg("границы диапазона должны быть числами, получено INTEGER и BOOLEAN")},
+		{"цикл (x в от(1, 5, 0)) { }", errMsg("шаг диапазона не может быть нулем")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestThreePartForLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать с: число = 0; цикл (создать i: число = 1; i <= 4; i += 1) { с += i; } с;", 10},
+		{`
+создать с: число = 0;
+цикл (создать i: число = 0; i < 3; i += 1) { с += 1; }
+цикл (создать i: число = 0; i < 3; i += 1) { с += 1; }
+с;`, 6},
+		{"создать с: число = 0; цикл (создать i: число = 0; i < 5; i += 1) { если (i % 2 == 0) { продолжить; } с += i; } с;", 4},
+		{"создать i: число = 0; цикл (i = 5; i < 8; i += 1) { } i;", 8},
+		{"цикл (создать i: число = 0; i < 3; i += 1) { } i;", errMsg("нет переменной: i")},
+		{"цикл (создать i: число = 0; i < 3; i += истина) { }", errMsg("разные типы: INTEGER + BOOLEAN")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestRangeObject(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1..10", "1..10"},
+		{"от(1, 10)", "1..10"},
+		{"от(10, 0, -2)", "от(10, 0, -2)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		rng, ok := evaluated.(*object.Range)
+		if !ok {
+			t.Errorf("object is not Range. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if rng.Inspect() != tt.expected {
+			t.Errorf("wrong range. expected=%q, got=%q", tt.expected, rng.Inspect())
+		}
+	}
+}
+
+func TestElseIfExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"создать x: число = -5; если (x < 0) { -1 } иначе если (x == 0) { 0 } иначе { 1 }", -1},
+		{"создать x: число = 0; если (x < 0) { -1 } иначе если (x == 0) { 0 } иначе { 1 }", 0},
+		{"создать x: число = 5; если (x < 0) { -1 } иначе если (x == 0) { 0 } иначе { 1 }", 1},
+		{"создать x: число = 5; если (x < 0) { -1 } иначе если (x < 3) { 0 } иначе если (x < 10) { 7 } иначе { 1 }", 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSwitchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"выбор (2) { случай 1: 10; случай 2, 3: 20; по_умолчанию: 30; }", 20},
+		{"выбор (3) { случай 1: 10; случай 2, 3: 20; по_умолчанию: 30; }", 20},
+		{"выбор (4) { случай 1: 10; случай 2, 3: 20; по_умолчанию: 30; }", 30},
+		{`выбор ("б") { случай "а": 1; случай "б": 2; }`, 2},
+		{`выбор ("в") { случай "а": 1; случай "б": 2; }`, nil},
+		{"выбор (1 + 1) { случай 1 * 2: 5; }", 5},
+		{`выбор (1) { случай "1": 1; по_умолчанию: 2; }`, 2},
+		{"выбор (1.0) { случай 1: 7; }", 7},
+		{"выбор (y) { случай 1: 1; }", errMsg("нет переменной: y")},
+		// пустая ветка возвращает пусто
+		{"выбор (1) { случай 1: }", nil},
+		{"выбор (2) { случай 1: 1; по_умолчанию: }", nil},
+		{"вывести(выбор (1) { случай 1: });", nil},
+		{"создать x: число = выбор (1) { случай 1: };", errMsg("неверная инициализация типа данных INT NULL")},
+		{"создать x: число = 1; x += выбор (1) { случай 1: };", errMsg("разные типы: INTEGER + NULL")},
+		{"длина([выбор (1) { случай 1: }])", 1},
+		{`
+создать с: число = 0;
+цикл (n в 1..5) {
+    выбор (n) {
+        случай 3: прервать;
+        по_умолчанию: с += n;
+    }
+}
+с;`, 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"а" == "а"`, true},
+		{`"а" == "б"`, false},
+		{`"а" != "б"`, true},
+		{`"а" + "б" == "аб"`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBlockScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"если (истина) { создать x: число = 1; } x;", errMsg("нет переменной: x")},
+		{"создать с: число = 0; цикл (n в 1..3) { создать д: число = n * 2; с += д; } с;", 12},
+		{"создать i: число = 0; цикл (i < 3) { создать x: число = i; i += 1; } i;", 3},
+		{"создать x: число = 1; если (истина) { создать x: число = 2; } x;", 1},
+		{"создать x: число = 1; если (истина) { создать x: число = 2; x = 3; } x;", 1},
+		{"создать x: число = 1; если (истина) { x = 5; } x;", 5},
+		{"создать x: число = 1; если (истина) { если (истина) { x += 5; } } x;", 6},
+		{"создать x: число = 1; если (истина) { создать x: число = 2; x; }", 2},
+		{"если (истина) { создать x: число = 1; создать x: число = 2; }", errMsg("переменная x уже существует = 1")},
+		{`
+создать счетчик: число = 0;
+создать увеличить: функция = функция() { счетчик += 1; };
+увеличить();
+увеличить();
+счетчик;`, 2},
+		{`
+создать x: число = 1;
+создать f: функция = функция() { создать x: число = 10; x; };
+f() + x;`, 11},
+		// тело функции выполняется в одной области видимости с аргументами
+		{`функция f(x: строка) { создать x: число = 1; вернуть x; } f("а");`, errMsg("переменная x уже существует = а")},
+		{"функция f(x) { создать x: число = 1; x } f(2);", errMsg("переменная x уже существует = 2")},
+		{"функция f(x) { если (истина) { создать x: число = 1; } x } f(2);", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestReassignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать x: число = 1; x = 2; x;", 2},
+		{"создать x: число = 1; x = 2 ** 100; x = 3; x;", 3},
+		{"создать x: дробь = 1.5; x = 2; x;", 2.0},
+		{`создать x: строка = "а"; x = "б"; x;`, "б"},
+		{`создать x: число = 1; x = "a";`, errMsg("нельзя присвоить значение типа строка переменной x типа число")},
+		{"создать x: число = 1; x = 1.5;", errMsg("нельзя присвоить значение типа дробь переменной x типа число")},
+		{"создать x: число = 1; x += 0.5;", errMsg("нельзя присвоить значение типа дробь переменной x типа число")},
+		{"создать x: булев = истина; x = [1];", errMsg("нельзя присвоить значение типа массив переменной x типа булев")},
+		{"x = 1;", errMsg("нет переменной: x")},
+		{"если (истина) { создать x: число = 1; } x = 2;", errMsg("нет переменной: x")},
+		{`
+создать x: число = 1;
+создать f: функция = функция() { x = 10; };
+f();
+x;`, 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestDeclaredTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать x: дробь = 1; x = 2; x;", 2.0},
+		{"создать x: большое_число = 1; x = 2 ** 100; x = 5; x;", 5},
+		{`создать f: функция = функция(a) { a = "б"; a; }; f(1);`, "б"},
+		{`создать с: строка = ""; цикл (x в [1, 2]) { x = "б"; с += x; } с;`, "бб"},
+		{`создать д: словарь = {}; д = [1];`, errMsg("нельзя присвоить значение типа массив переменной д типа словарь")},
+		{`создать x: число = 1; если (истина) { x = "а"; }`, errMsg("нельзя присвоить значение типа строка переменной x типа число")},
+		{`создать x: число = 1; если (истина) { создать x: строка = "а"; x = "б"; x; }`, "б"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestVariableType(t *testing.T) {
+	env := object.NewEnvironment()
+	program := parser.New(`создать x: дробь = 1; создать ф: функция = функция(a) { a };`).ParseProgram()
+	Eval(program, env)
+	env.Set("арг", &object.Integer{Value: 1})
+
+	tests := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"x", "дробь", true},
+		{"ф", "функция", true},
+		{"арг", "число", true},
+		{"y", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := VariableType(env, tt.name)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("VariableType(%q) wrong. expected=(%q, %t), got=(%q, %t)",
+				tt.name, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"постоянная N: число = 10; N * 2;", 20},
+		{"постоянная N: число = 10; N = 5;", errMsg("нельзя изменить постоянную N")},
+		{"постоянная N: число = 10; N += 1;", errMsg("нельзя изменить постоянную N")},
+		{"постоянная N: число = 10; если (истина) { N = 5; }", errMsg("нельзя изменить постоянную N")},
+		{"постоянная N: число = 10; создать N: число = 5;", errMsg("переменная N уже существует = 10")},
+		{"постоянная N: число = 10; если (истина) { создать N: число = 5; N = 6; N; }", 6},
+		{"постоянная М: массив = [1, 2]; М[0] = 5;", errMsg("нельзя изменить постоянную М")},
+		{"постоянная М: массив = [[1], [2]]; М[0][0] = 5;", errMsg("нельзя изменить постоянную М")},
+		{"функция дай() { постоянная М: массив = [1]; вернуть М; } дай()[0] = 2;", errMsg("нельзя изменить постоянное значение")},
+		{"постоянная М: массив = [1, 2]; добавить(М, 3);", errMsg("нельзя изменить постоянный массив")},
+		{`постоянная Д: словарь = {"а": 1}; Д["б"] = 2;`, errMsg("нельзя изменить постоянную Д")},
+		{`постоянная Д: словарь = {"а": 1}; удалить(Д, "а");`, errMsg("нельзя изменить постоянный словарь")},
+		{"создать м: массив = [1, 2]; постоянная М: массив = м; м[0] = 5; М[0];", 1},
+		{"постоянная М: массив = [1, 2]; создать м: массив = М; длина(добавить([0], М[1]));", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFoldConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"60 * 60 * 24", "86400"},
+		{"-(2 + 3)", "-5"},
+		{"x * (2 + 3)", "(x * 5)"},
+		{"2 ** 100", "1267650600228229401496703205376"},
+		{"1 < 2", "истина"},
+		{`"при" + "вет"`, "привет"},
+		{"1 / 0", "(1 / 0)"},
+		{"истина и ложь", "(истина && ложь)"},
+		{"создать x: число = 1 + 1;", "создать x: число = 2;"},
+		{"f(1 + 1, [2 * 2])", "f(2, [4])"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(tt.input).ParseProgram()
+		foldConstants(program)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong folding of %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestTypedFunctions(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    interface{}
+		expectedPos string
+	}{
+		{"создать ф: функция = функция(x: число, y: число): число { x + y }; ф(1, 2);", 3, ""},
+		{"создать ф: функция = функция(x: дробь): дробь { x }; ф(1);", 1.0, ""},
+		{"создать ф: функция = функция(x, y: число) { x }; ф(\"а\", 2);", "а", ""},
+		{"создать ф: функция = функция(x: число): дробь { x }; ф(2);", 2.0, ""},
+		{"создать ф: функция = функция(x: число) { x }; ф(\"а\");", "функция ф: аргумент x должен иметь тип число, получено строка", "1:47"},
+		{"создать ф: функция = функция(x: число): строка { x }; ф(1);", "функция ф должна вернуть строка, получено число", "1:55"},
+		{"создать ф: функция = функция(): число { }; ф();", "функция ф должна вернуть число, получено пусто", "1:44"},
+		{"функция(x: булев) { x }(1);", "функция: аргумент x должен иметь тип булев, получено число", "1:1"},
+		{"создать ф: функция = функция(x: число) { x = \"а\"; }; ф(1);", "нельзя присвоить значение типа строка переменной x типа число", "1:44"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				testStringObject(t, evaluated, expected)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+			if errObj.Pos.String() != tt.expectedPos {
+				t.Errorf("wrong error position for %q. expected=%q, got=%q", tt.input, tt.expectedPos, errObj.Pos.String())
+			}
+		}
+	}
+}
+
+func TestFunctionInspect(t *testing.T) {
+	evaluated := testEval("создать ф: функция = функция(x: число, y): булев { истина }; ф;")
+	expected := "функция ф(x: число, y): булев {\nистина\n}"
+	if evaluated.Inspect() != expected {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expected, evaluated.Inspect())
+	}
+}
+
+func TestFunctionArity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"создать мин: функция = функция(x, y) { x }; мин(1);", errMsg("функция мин: неверное количество аргументов получено 1, надо 2")},
+		{"создать мин: функция = функция(x, y) { x }; мин(1, 2, 3);", errMsg("функция мин: неверное количество аргументов получено 3, надо 2")},
+		{"функция() { 1 }(1);", errMsg("функция: неверное количество аргументов получено 1, надо 0")},
+		{"создать ф: функция = функция(x, y = 10) { x + y }; ф(1);", 11},
+		{"создать ф: функция = функция(x, y = 10) { x + y }; ф(1, 2);", 3},
+		{"создать ф: функция = функция(x, y = x * 2) { x + y }; ф(5);", 15},
+		{"создать ф: функция = функция(x, y = 10) { x + y }; ф();", errMsg("функция ф: неверное количество аргументов получено 0, надо от 1 до 2")},
+		{"создать ф: функция = функция(x = y) { x }; ф();", errMsg("нет переменной: y")},
+		{"создать ф: функция = функция(x: число = \"а\") { x }; ф();", errMsg("функция ф: аргумент x должен иметь тип число, получено строка")},
+		{"создать ф: функция = функция(...числа) { длина(числа) }; ф();", 0},
+		{"создать ф: функция = функция(...числа) { длина(числа) }; ф(1, 2, 3);", 3},
+		{"создать ф: функция = функция(x, ...числа) { x + длина(числа) }; ф(10, 2, 3);", 12},
+		{"создать ф: функция = функция(x, ...числа) { x }; ф();", errMsg("функция ф: неверное количество аргументов получено 0, надо не меньше 1")},
+		{"создать ф: функция = функция(...числа: число) { числа[1] }; ф(1, 2);", 2},
+		{"создать ф: функция = функция(...числа: число) { 0 }; ф(1, \"а\");", errMsg("функция ф: элементы числа должны иметь тип число, получено строка")},
+		{`
+создать сумма: функция = функция(...числа: число) {
+    создать с: число = 0;
+    цикл (x в числа) { с += x; }
+    вернуть с;
+};
+сумма(1, 2, 3, 4);`, 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"функция сумма(x, y) { x + y } сумма(1, 2);", 3},
+		{"создать р: число = сумма(1, 2); функция сумма(x, y) { x + y } р;", 3},
+		{`
+создать р: число = главная();
+
+функция главная() {
+    вернуть чет(10);
+}
+
+функция чет(n) {
+    если (n == 0) { вернуть 1; }
+    вернуть нечет(n - 1);
+}
+
+функция нечет(n) {
+    если (n == 0) { вернуть 0; }
+    вернуть чет(n - 1);
+}
+
+р;`, 1},
+		{`
+функция главная() { вернуть чет(7); }
+функция чет(n) { если (n == 0) { вернуть 1; } вернуть нечет(n - 1); }
+функция нечет(n) { если (n == 0) { вернуть 0; } вернуть чет(n - 1); }
+главная();`, 0},
+		{"функция ф() { внутр() * 2 } функция внешн() { функция внутр() { 21 } ф() } внешн();", errMsg("нет переменной: внутр")},
+		{"функция ф() { вернуть внутр(); функция внутр() { 21 } } ф();", 21},
+		{"функция ф() { 1 } функция ф() { 2 }", errMsg("переменная ф уже существует")},
+		{"создать ф: число = 1; функция ф() { 2 }", errMsg("переменная ф уже существует")},
+		{"функция ф() { 2 } создать ф: число = 1;", errMsg("переменная ф уже существует")},
+		{"функция ф() { 1 } ф = 5;", errMsg("нельзя присвоить значение типа число переменной ф типа функция")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+
+	evaluated := testEval("функция ф(x: число): число { x } ф;")
+	expected := "функция ф(x: число): число {\nx\n}"
+	if evaluated.Inspect() != expected {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expected, evaluated.Inspect())
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	input := `
+функция главная() {
+    вернуть делить(10, 0);
+}
+функция делить(x, y) {
+    вернуть проверить([1, 2, 3, 4, 5, 6, 7, 8, 9]) / y;
+}
+функция проверить(x) {
+    вернуть x + 1;
+}
+главная();`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Pos.String() != "9:15" {
+		t.Errorf("wrong error position. expected=%q, got=%q", "9:15", errObj.Pos.String())
+	}
+
+	expected := []string{
+		"проверить([1, 2, 3, 4, 5, 6...) в 6:13",
+		"делить(10, 0) в 3:13",
+		"главная() в 11:1",
+	}
+	if len(errObj.Trace) != len(expected) {
+		t.Fatalf("wrong trace length. expected=%d, got=%d", len(expected), len(errObj.Trace))
+	}
+	for i, frame := range errObj.Trace {
+		if frame.String() != expected[i] {
+			t.Errorf("wrong frame %d. expected=%q, got=%q", i, expected[i], frame.String())
+		}
+	}
+
+	tests := []string{
+		// ошибка на верхнем уровне программы
+		"1 + истина;",
+		// ошибка при проверке аргументов возникает до входа в функцию
+		"функция ф(x: число) { x } ф(\"а\");",
+	}
+	for _, input := range tests {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q", input)
+			continue
+		}
+		if len(errObj.Trace) != 0 {
+			t.Errorf("unexpected trace for %q: %v", input, errObj.Trace)
+		}
+	}
+
+	// безымянная функция и функция, вызванная из замыкания
+	evaluated = testEval("создать ф: функция = функция() { функция(x) { x / 0 } }; ф()(5);")
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if len(errObj.Trace) != 1 || errObj.Trace[0].String() != "функция(5) в 1:58" {
+		t.Errorf("wrong trace. got=%v", errObj.Trace)
+	}
+
+	// краткая запись аргументов разных типов
+	argTests := []struct {
+		input    string
+		expected string
+	}{
+		{"создать g: функция = функция() {}; создать f: функция = функция(x) { вернуть 1 / 0; }; f(g());", "f(пусто) в 1:88"},
+		{"функция f(x) { 1 / 0 } f(f);", "f(функция f) в 1:24"},
+		{"функция f(x) { 1 / 0 } f({\"а\": 1, 2: [3]});", "f({\"а\": 1, 2: [3]}) в 1:24"},
+		{"функция f(x) { 1 / 0 } f({\"один\": 1, \"два\": 2, \"три\": 3});", "f({\"один\": 1, \"два\"...) в 1:24"},
+		// большие числа не переводятся в десятичную запись целиком
+		{"функция ф(n, акк) { если (n == 0) { вернуть акк / 0; } вернуть ф(n - 1, акк * n); } ф(200, 1);", "ф(0, число из ~376 цифр) в 1:64 (хвостовых вызовов: 200)"},
+		{"функция f(x) { 1 / 0 } f(9223372036854775807 * 100);", "f(92233720368547758...) в 1:24"},
+		// аргументы записываются в момент вызова, а не в момент ошибки
+		{"функция f(м) { добавить(м, 3); м[0] = 9; 1 / 0 } f([1, 2]);", "f([1, 2]) в 1:50"},
+		{"функция f(n, м) { если (n == 0) { вернуть 1 / 0; } добавить(м, n); вернуть f(n - 1, м); } f(2, []);", "f(0, [2, 1]) в 1:76 (хвостовых вызовов: 2)"},
+	}
+	for _, tt := range argTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q", tt.input)
+			continue
+		}
+		if len(errObj.Trace) != 1 || errObj.Trace[0].String() != tt.expected {
+			t.Errorf("wrong trace for %q. expected=%q, got=%v", tt.input, tt.expected, errObj.Trace)
+		}
+	}
+}
+
+func TestRecursionDepthLimit(t *testing.T) {
+	evaluated := testEval("создать ф: функция = функция(x) { вернуть ф(x); }; ф(1);")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	expected := fmt.Sprintf("слишком глубокая рекурсия: больше %d вызовов", DefaultMaxCallDepth)
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+	}
+	if len(errObj.Trace) != maxTraceFrames {
+		t.Fatalf("wrong trace length. expected=%d, got=%d", maxTraceFrames, len(errObj.Trace))
+	}
+	if errObj.Trace[0].Depth != DefaultMaxCallDepth {
+		t.Errorf("wrong depth of the last frame. expected=%d, got=%d", DefaultMaxCallDepth, errObj.Trace[0].Depth)
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"функция сумма(n) { если (n == 0) { вернуть 0; } вернуть n + сумма(n - 1); } сумма(49);", 1225},
+		{"функция сумма(n) { если (n == 0) { вернуть 0; } вернуть n + сумма(n - 1); } сумма(50);", errMsg("слишком глубокая рекурсия: больше 50 вызовов")},
+		{"функция чет(n) { если (n == 0) { вернуть 1; } вернуть нечет(n - 1); } функция нечет(n) { чет(n - 1) } чет(100);", errMsg("слишком глубокая рекурсия: больше 50 вызовов")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithDepth(tt.input, 50)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	// вызовы самой себя в конце функции не увеличивают глубину стека,
+	// поэтому маленький предел не мешает глубокой рекурсии
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"функция сумма(n, акк = 0) { если (n == 0) { вернуть акк; } вернуть сумма(n - 1, акк + n); } сумма(1000000);", 500000500000},
+		{`
+создать счет: функция = функция(n, акк) {
+    выбор (n) {
+        случай 0: вернуть акк;
+        по_умолчанию: вернуть счет(n - 1, акк + 2);
+    }
+};
+счет(5000, 0);`, 10000},
+		{"функция ф(n) { цикл (x в 0..1) { если (n > 0) { вернуть ф(n - 1); } } вернуть 7; } ф(1000);", 7},
+		{"функция ф(n) { если (n == 0) { вернуть 0; } вернуть 1 + ф(n - 1); } ф(1000);", errMsg("слишком глубокая рекурсия: больше 100 вызовов")},
+		{"функция чет(n) { если (n == 0) { вернуть 1; } вернуть нечет(n - 1); } функция нечет(n) { если (n == 0) { вернуть 0; } вернуть чет(n - 1); } чет(1000);", errMsg("слишком глубокая рекурсия: больше 100 вызовов")},
+		{"функция ф(n): число { если (n == 0) { вернуть \"а\"; } вернуть ф(n - 1); } ф(5);", errMsg("функция ф должна вернуть число, получено строка")},
+		{"функция ф(n) { вернуть ф(); } ф(1);", errMsg("функция ф: неверное количество аргументов получено 0, надо 1")},
+		// вызов с теми же аргументами не заменяет текущий, но может завершиться,
+		// если меняется что-то снаружи функции
+		{"создать i: число = 0; функция ф(x) { i += 1; если (i == 5) { вернуть x; } вернуть ф(x); } ф(7);", 7},
+		{"функция ф(x, y) { вернуть ф(x, y); } ф(1, \"а\");", errMsg("слишком глубокая рекурсия: больше 100 вызовов")},
+		{"функция ф(м) { если (длина(м) == 1000) { вернуть длина(м); } добавить(м, 1); вернуть ф(м); } ф([]);", 1000},
+		// вернуть внутри аргумента вызова не хвостовой: f(n - 1) выполняется сразу
+		{"функция f(n) { если (n == 0) { вернуть 0; } вывести(если (истина) { вернуть f(n - 1); }); вернуть 5; } f(2);", 5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithDepth(tt.input, 100)
+		testObject(t, evaluated, tt.expected)
+	}
+
+	// после хвостовых вызовов в стеке остается только последний
+	evaluated := testEval("функция ф(n) { если (n == 0) { вернуть 1 / n; } вернуть ф(n - 1); } ф(10000);")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if len(errObj.Trace) != 1 || errObj.Trace[0].String() != "ф(0) в 1:57 (хвостовых вызовов: 10000)" || errObj.Trace[0].Depth != 1 {
+		t.Errorf("wrong trace. got=%v", errObj.Trace)
+	}
+
+	evaluated = testEval("функция ф(n) { вернуть ф(); } ф(1);")
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Pos.String() != "1:24" {
+		t.Errorf("wrong error position. got=%+v", evaluated)
+	}
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedPos     string
+	}{
+		{"1 / 0", "деление на ноль", "1:3"},
+		{"10 % 0", "деление на ноль", "1:4"},
+		{"1.5 / 0", "деление на ноль", "1:5"},
+		{"создать x: число = 0;\nвывести(5 / x);", "деление на ноль", "2:11"},
+		{"создать x: число = 1; x /= 0;", "деление на ноль", "1:25"},
+		{"100000000000000000000 / 0", "деление на ноль", "1:23"},
+		{"100000000000000000000 % 0", "деление на ноль", "1:23"},
+		{"10 ** 10000000", "слишком большое число", "1:4"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+		if errObj.Pos.String() != tt.expectedPos {
+			t.Errorf("wrong error position for %q. expected=%q, got=%q",
+				tt.input, tt.expectedPos, errObj.Pos.String())
+		}
+	}
+}
+
+func TestLargeIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"9223372036854775806 + 1", 9223372036854775807},
+		{"-9223372036854775807 - 1", -9223372036854775808},
+		{"3037000499 * 3037000499", 9223372030926249001},
+		{"2 ** 62", 4611686018427387904},
+		{"(-2) ** 63", -9223372036854775808},
+		{"(-9223372036854775807 - 1) % -1", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4611686018427387904 * 2", "9223372036854775808"},
+		{"2 ** 63", "9223372036854775808"},
+		{"2 ** 100", "1267650600228229401496703205376"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"100000000000000000000", "100000000000000000000"},
+		{"100000000000000000000 % 7", "2"},
+		{"2 ** 100 / 2 ** 99", "2"},
+		{"(2 ** 64 - 2 ** 64) + 5", "5"},
+		{`в_число("123456789012345678901234567890")`, "123456789012345678901234567890"},
+		{"в_число(1e20)", "100000000000000000000"},
+		{`
+создать факториал: функция = функция(n) {
+	если (n == 0) {
+		вернуть 1;
+	}
+	вернуть n * факториал(n - 1);
+};
+факториал(30);`, "265252859812191058636308480000000"},
+		{"создать б: большое_число = 2 ** 70; б;", "1180591620717411303424"},
+		{"создать м: большое_число = 5; м;", "5"},
+		{"создать ч: число = 2 ** 70; ч;", "1180591620717411303424"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if !isInteger(evaluated) {
+			t.Errorf("object is not integer. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong value. expected=%s, got=%s", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestBigIntegerNormalization(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"2 ** 100 > 2 ** 99", true},
+		{"2 ** 64 == 2 ** 64", true},
+		{"2 ** 64 != 2 ** 64 + 1", true},
+		{"2 ** 64 < 5", false},
+		{"2 ** 64 - 2 ** 64 + 5", 5},
+		{"(9223372036854775807 + 1) - 1", 9223372036854775807},
+		{"2 ** 64 * 0.5", 9223372036854775808.0},
+		{"в_дробь(2 ** 64)", 18446744073709551616.0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `создать два: строка = "два";
+	{
+		"один": 10 - 9,
+		два: 1 + 1,
+		"тр" + "и": 6 / 2,
+		4: 4,
+		истина: 5,
+		ложь: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := []struct {
+		key   object.Hashable
+		value int64
+	}{
+		{&object.String{Value: "один"}, 1},
+		{&object.String{Value: "два"}, 2},
+		{&object.String{Value: "три"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
+	}
+
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
+	}
+
+	for _, tt := range expected {
+		value, ok := result.Get(tt.key)
+		if !ok {
+			t.Errorf("no pair for key %s", tt.key.Inspect())
+			continue
+		}
+		testIntegerObject(t, value, tt.value)
+	}
+
+	if result.Inspect() != `{"один": 1, "два": 2, "три": 3, 4: 4, истина: 5, ложь: 6}` {
+		t.Errorf("wrong Inspect. got=%q", result.Inspect())
+	}
+}
+
+func TestHashIndexAndBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"а": 5}["а"]`, 5},
+		{`{"а": 5}["б"]`, nil},
+		{`создать к: строка = "а"; {"а": 5}[к]`, 5},
+		{`{}["а"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{истина: 5}[истина]`, 5},
+		{`{2 ** 70: 1}[2 ** 70]`, 1},
+		{`длина({"1": 1, 1: 2})`, 2},
+		{`{"1": 1, 1: 2}[1]`, 2},
+		{`создать д: словарь = {"а": 1}; д["а"] = 2; длина(д);`, 1},
+		{`создать д: словарь = {}; д["к"] = 7; д["к"];`, 7},
+		{`создать д: словарь = {"к": 1}; д["к"] = 2; длина(д);`, 1},
+		{`создать д: словарь = {"к": 1}; д["к"] += 10; д["к"];`, 11},
+		{`создать д: словарь = {"а": 1, "б": 2}; длина(ключи(д));`, 2},
+		{`создать д: словарь = {"а": 1, "б": 2}; есть_ключ(д, "б");`, true},
+		{`создать д: словарь = {"а": 1, "б": 2}; есть_ключ(д, "в");`, false},
+		{`создать д: словарь = {"а": 1, "б": 2}; удалить(д, "а");`, 1},
+		{`создать д: словарь = {"а": 1, "б": 2}; удалить(д, "а"); есть_ключ(д, "а");`, false},
+		{`создать д: словарь = {"а": 1}; удалить(д, "я");`, nil},
+		{`создать мас: массив = [1, 2]; мас[0] = 5; мас[0];`, 5},
+		{`{"а": 1}[[1]]`, errMsg("нельзя использовать как ключ словаря: ARRAY")},
+		{`{[1]: 1}`, errMsg("нельзя использовать как ключ словаря: ARRAY")},
+		{`создать д: словарь = {}; д["к"] += 1;`, errMsg("нет ключа: к")},
+		{`ключи([1])`, errMsg("первый аргумент должен быть словарем, получено ARRAY")},
+		{`создать мас: массив = [1, 2]; мас[2] = 5;`, errMsg("индекс 2 вне границ массива длины 2")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestHashKeysAndValuesOrder(t *testing.T) {
+	input := `создать д: словарь = {"в": 3, "а": 1, "б": 2};
+	удалить(д, "а");
+	д["г"] = 4;
+	[ключи(д), значения(д)]`
+
+	evaluated := testEval(input)
+	if evaluated.Inspect() != "[[в, б, г], [3, 2, 4]]" {
+		t.Errorf("wrong order. got=%q", evaluated.Inspect())
+	}
+}
+
+func BenchmarkLoop(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		input string
+	}{
+		{"пустое тело", "создать i: число = 0; цикл (i < 10000) { i = i + 1; }"},
+		{"объявление в теле", "создать i: число = 0; цикл (i < 10000) { создать шаг: число = 1; i = i + шаг; }"},
+		{"перебор", "создать с: число = 0; цикл (x в 1..10000) { с += x; }"},
+	}
+
+	for _, bm := range benchmarks {
+		program := parser.New(bm.input).ParseProgram()
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Eval(program, object.NewEnvironment())
+			}
+		})
+	}
+}
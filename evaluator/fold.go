@@ -0,0 +1,157 @@
+package evaluator
+
+import (
+	"strconv"
+
+	"github.com/usamaroman/uman/ast"
+	"github.com/usamaroman/uman/object"
+	"github.com/usamaroman/uman/token"
+)
+
+// foldConstants заранее вычисляет арифметические выражения, все операнды которых
+// являются литералами, и заменяет их литералом с результатом: 60 * 60 * 24
+// превращается в 86400. Выражения, вычисление которых заканчивается ошибкой,
+// не меняются, чтобы ошибка была выдана при выполнении в нужном месте
+func foldConstants(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, stmt := range node.Statements {
+			foldConstants(stmt)
+		}
+	case *ast.BlockStatement:
+		if node == nil {
+			return
+		}
+		for _, stmt := range node.Statements {
+			foldConstants(stmt)
+		}
+	case *ast.ExpressionStatement:
+		node.Expression = foldExpression(node.Expression)
+	case *ast.VariableStatement:
+		node.Value = foldExpression(node.Value)
+	case *ast.ReturnStatement:
+		node.Value = foldExpression(node.Value)
+	case *ast.FunctionDeclaration:
+		foldConstants(node.Function.Body)
+	}
+}
+
+func foldExpression(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		exp.Right = foldExpression(exp.Right)
+		if !isLiteral(exp.Right) {
+			return exp
+		}
+		return foldedLiteral(exp, evalPrefixExpression(exp.Operator, literalValue(exp.Right)))
+	case *ast.InfixExpression:
+		exp.Left = foldExpression(exp.Left)
+		exp.Right = foldExpression(exp.Right)
+		// && и || вычисляют правую часть не всегда, а .. создает диапазон
+		if exp.Operator == token.AND || exp.Operator == token.OR || exp.Operator == token.DOTDOT {
+			return exp
+		}
+		if !isLiteral(exp.Left) || !isLiteral(exp.Right) {
+			return exp
+		}
+		return foldedLiteral(exp, evalInfixExpression(exp.Operator, literalValue(exp.Left), literalValue(exp.Right)))
+	case *ast.AssignExpression:
+		exp.Value = foldExpression(exp.Value)
+	case *ast.IfExpression:
+		exp.Condition = foldExpression(exp.Condition)
+		foldConstants(exp.Consequence)
+		if exp.ElseIf != nil {
+			foldExpression(exp.ElseIf)
+		}
+		foldConstants(exp.Alternative)
+	case *ast.SwitchExpression:
+		exp.Subject = foldExpression(exp.Subject)
+		for _, c := range exp.Cases {
+			foldConstants(c.Body)
+		}
+		foldConstants(exp.Default)
+	case *ast.ForLoopExpression:
+		if exp.Init != nil {
+			foldConstants(exp.Init)
+		}
+		exp.Condition = foldExpression(exp.Condition)
+		exp.Post = foldExpression(exp.Post)
+		foldConstants(exp.Statement)
+	case *ast.ForEachExpression:
+		exp.Iterable = foldExpression(exp.Iterable)
+		foldConstants(exp.Statement)
+	case *ast.FunctionLiteral:
+		foldConstants(exp.Body)
+	case *ast.CallExpression:
+		for i, arg := range exp.Arguments {
+			exp.Arguments[i] = foldExpression(arg)
+		}
+	case *ast.IndexExpression:
+		exp.Left = foldExpression(exp.Left)
+		exp.Index = foldExpression(exp.Index)
+	case *ast.ArrayLiteral:
+		for i, element := range exp.Elements {
+			exp.Elements[i] = foldExpression(element)
+		}
+	case *ast.HashLiteral:
+		for i, pair := range exp.Pairs {
+			exp.Pairs[i].Key = foldExpression(pair.Key)
+			exp.Pairs[i].Value = foldExpression(pair.Value)
+		}
+	}
+	return exp
+}
+
+func isLiteral(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.BooleanLiteral, *ast.StringLiteral:
+		return true
+	default:
+		return false
+	}
+}
+
+// literalValue возвращает значение литерала так же, как его вычислил бы Eval
+func literalValue(exp ast.Expression) object.Object {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		if exp.Big != nil {
+			return &object.BigInteger{Value: exp.Big}
+		}
+		return &object.Integer{Value: exp.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: exp.Value}
+	case *ast.BooleanLiteral:
+		return nativeBoolToBooleanObj(exp.Value)
+	case *ast.StringLiteral:
+		return &object.String{Value: exp.Value}
+	default:
+		return nil
+	}
+}
+
+// foldedLiteral возвращает литерал со значением value на месте выражения exp.
+// Если значение не удалось вычислить, выражение остается как есть
+func foldedLiteral(exp ast.Expression, value object.Object) ast.Expression {
+	pos := exp.Pos()
+
+	switch value := value.(type) {
+	case *object.Integer:
+		literal := strconv.FormatInt(value.Value, 10)
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT_VAL, Literal: literal, Pos: pos}, Value: value.Value}
+	case *object.BigInteger:
+		literal := value.Value.String()
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT_VAL, Literal: literal, Pos: pos}, Big: value.Value}
+	case *object.Float:
+		return &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT_VAL, Literal: value.Inspect(), Pos: pos}, Value: value.Value}
+	case *object.Boolean:
+		if value.Value {
+			return &ast.BooleanLiteral{Token: token.Token{Type: token.TRUE, Literal: "истина", Pos: pos}, Value: true}
+		}
+		return &ast.BooleanLiteral{Token: token.Token{Type: token.FALSE, Literal: "ложь", Pos: pos}, Value: false}
+	case *object.String:
+		return &ast.StringLiteral{Token: token.Token{Type: token.STRING_VAL, Literal: value.Value, Pos: pos}, Value: value.Value}
+	default:
+		return exp
+	}
+}
@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/usamaroman/uman/object"
+)
+
+// Арифметика над int64 с проверкой переполнения: второй результат
+// равен false, если точный результат не помещается в int64
+
+func addInt64(a, b int64) (int64, bool) {
+	result := a + b
+	if (a > 0 && b > 0 && result < 0) || (a < 0 && b < 0 && result >= 0) {
+		return 0, false
+	}
+	return result, true
+}
+
+func subInt64(a, b int64) (int64, bool) {
+	result := a - b
+	if (a >= 0 && b < 0 && result < 0) || (a < 0 && b > 0 && result >= 0) {
+		return 0, false
+	}
+	return result, true
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	result := a * b
+	if result/b != a {
+		return 0, false
+	}
+	return result, true
+}
+
+func divInt64(a, b int64) (int64, bool) {
+	if a == math.MinInt64 && b == -1 {
+		return 0, false
+	}
+	return a / b, true
+}
+
+func negInt64(a int64) (int64, bool) {
+	if a == math.MinInt64 {
+		return 0, false
+	}
+	return -a, true
+}
+
+// powInt64 возводит целое число в неотрицательную степень
+func powInt64(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		var ok bool
+		if exp&1 == 1 {
+			if result, ok = mulInt64(result, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, ok = mulInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// maxBigIntBits ограничивает размер результата возведения в степень,
+// чтобы случайное 10 ** 1000000000 не заняло всю память
+const maxBigIntBits = 1 << 20
+
+// evalBigIntegerInfixExpression вычисляет выражение над целыми числами,
+// хотя бы одно из которых не помещается в int64
+func evalBigIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return normalizeBigInt(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return normalizeBigInt(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return normalizeBigInt(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("деление на ноль")
+		}
+		return normalizeBigInt(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("деление на ноль")
+		}
+		return normalizeBigInt(new(big.Int).Rem(leftVal, rightVal))
+	case "**":
+		if rightVal.Sign() < 0 {
+			return &object.Float{Value: math.Pow(toFloat(left), toFloat(right))}
+		}
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 &&
+			(!rightVal.IsInt64() || rightVal.Int64() > maxBigIntBits/int64(leftVal.BitLen())) {
+			return newError("слишком большое число")
+		}
+		return normalizeBigInt(new(big.Int).Exp(leftVal, rightVal, nil))
+	case ">":
+		return nativeBoolToBooleanObj(leftVal.Cmp(rightVal) > 0)
+	case "<":
+		return nativeBoolToBooleanObj(leftVal.Cmp(rightVal) < 0)
+	case ">=":
+		return nativeBoolToBooleanObj(leftVal.Cmp(rightVal) >= 0)
+	case "<=":
+		return nativeBoolToBooleanObj(leftVal.Cmp(rightVal) <= 0)
+	case "==":
+		return nativeBoolToBooleanObj(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObj(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("неизвестный оператор: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	default:
+		return new(big.Int)
+	}
+}
+
+// normalizeBigInt возвращает обычное число, если значение помещается в int64,
+// так что большие числа не замедляют вычисления с маленькими
+func normalizeBigInt(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
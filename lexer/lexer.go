@@ -1,7 +1,10 @@
 package lexer
 
 import (
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/usamaroman/uman/token"
 )
@@ -11,20 +14,62 @@ type Lexer struct {
 	position     int // current position in input (points to current char)
 	readPosition int // current reading position in input (after current char)
 	ch           rune
+
+	filename string
+	offset   int // byte offset of current char
+	line     int // line of current char
+	column   int // column of current char
+
+	comments []string // comments read before the next token
+	onError  ErrorHandler
 }
 
+// ErrorHandler получает ошибки, найденные лексером
+type ErrorHandler func(pos token.Position, msg string)
+
 func New(input string) *Lexer {
+	return NewFile("", input)
+}
+
+// NewFile создает лексер, позиции токенов которого ссылаются на файл filename
+func NewFile(filename, input string) *Lexer {
 	in := []rune(input)
-	l := &Lexer{input: in}
+	l := &Lexer{
+		input:    in,
+		filename: filename,
+		line:     1,
+		column:   1,
+	}
 	l.readChar()
 	return l
 }
 
-func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+// SetErrorHandler задает функцию, которая будет вызвана для каждой ошибки лексера
+func (l *Lexer) SetErrorHandler(handler ErrorHandler) {
+	l.onError = handler
+}
 
+func (l *Lexer) NextToken() token.Token {
 	l.skipWhitespace()
 
+	pos := l.currPosition()
+	tok := l.readToken()
+	tok.Pos = pos
+	tok.Comments = l.comments
+	l.comments = nil
+
+	return tok
+}
+
+func (l *Lexer) error(pos token.Position, msg string) {
+	if l.onError != nil {
+		l.onError(pos, msg)
+	}
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case ':':
 		tok = token.New(token.COLON, l.ch)
@@ -50,6 +95,34 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = token.New(token.ASSIGN, l.ch)
 		}
+	case '&':
+		if l.peekRune() == '&' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.illegal()
+		}
+	case '|':
+		if l.peekRune() == '|' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.illegal()
+		}
+	case '.':
+		if l.peekRune() == '.' && l.peekRuneAt(2) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if l.peekRune() == '.' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.DOTDOT, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.illegal()
+		}
 	case ';':
 		tok = token.New(token.SEMICOLON, l.ch)
 	case ',':
@@ -63,13 +136,19 @@ func (l *Lexer) NextToken() token.Token {
 	case '}':
 		tok = token.New(token.RBRACE, l.ch)
 	case '+':
-		tok = token.New(token.PLUS, l.ch)
+		tok = l.readOperator(token.PLUS, '=', token.PLUS_ASSIGN)
 	case '-':
-		tok = token.New(token.MINUS, l.ch)
+		tok = l.readOperator(token.MINUS, '=', token.MINUS_ASSIGN)
 	case '*':
-		tok = token.New(token.ASTERISK, l.ch)
+		if l.peekRune() == '*' {
+			tok = l.readOperator(token.ASTERISK, '*', token.POWER)
+		} else {
+			tok = l.readOperator(token.ASTERISK, '=', token.ASTERISK_ASSIGN)
+		}
 	case '/':
-		tok = token.New(token.SLASH, l.ch)
+		tok = l.readOperator(token.SLASH, '=', token.SLASH_ASSIGN)
+	case '%':
+		tok = l.readOperator(token.PERCENT, '=', token.PERCENT_ASSIGN)
 	case '>':
 		if l.peekRune() == '=' {
 			ch := l.ch
@@ -87,8 +166,9 @@ func (l *Lexer) NextToken() token.Token {
 			tok = token.New(token.LT, l.ch)
 		}
 	case '"':
-		tok.Type = token.STRING_VAL
-		tok.Literal = l.readString()
+		tok = l.readString()
+	case '`':
+		tok = l.readRawString()
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -102,11 +182,9 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Literal = l.readDigit()
-			tok.Type = token.INT_VAL
-			return tok
+			return l.readNumber()
 		} else {
-			tok = token.New(token.ILLEGAL, l.ch)
+			tok = l.illegal()
 		}
 	}
 
@@ -115,9 +193,26 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+// readOperator возвращает двухсимвольный токен long, если за текущим
+// символом следует next, и односимвольный токен short в противном случае
+func (l *Lexer) readOperator(short token.TokenType, next rune, long token.TokenType) token.Token {
+	if l.peekRune() != next {
+		return token.New(short, l.ch)
+	}
+
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: long, Literal: string(ch) + string(l.ch)}
+}
+
+func (l *Lexer) illegal() token.Token {
+	l.error(l.currPosition(), "неизвестный символ "+strconv.Quote(string(l.ch)))
+	return token.New(token.ILLEGAL, l.ch)
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
 
@@ -133,8 +228,36 @@ func (l *Lexer) readDigit() string {
 	return string(l.input[position:l.position])
 }
 
+// readNumber читает целое число или дробь вида 3.14, 1e10, 2.5e-3
+func (l *Lexer) readNumber() token.Token {
+	position := l.position
+	tokenType := token.TokenType(token.INT_VAL)
+
+	l.readDigit()
+
+	if l.ch == '.' && isDigit(l.peekRuneAt(1)) {
+		tokenType = token.FLOAT_VAL
+		l.readChar()
+		l.readDigit()
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekRuneAt(1)
+		if isDigit(next) || (next == '+' || next == '-') && isDigit(l.peekRuneAt(2)) {
+			tokenType = token.FLOAT_VAL
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			l.readDigit()
+		}
+	}
+
+	return token.Token{Type: tokenType, Literal: string(l.input[position:l.position])}
+}
+
 func isLetter(ch rune) bool {
-	return unicode.Is(unicode.Cyrillic, ch) || unicode.Is(unicode.Latin, ch)
+	return unicode.Is(unicode.Cyrillic, ch) || unicode.Is(unicode.Latin, ch) || ch == '_'
 }
 
 func isDigit(ch rune) bool {
@@ -142,6 +265,16 @@ func isDigit(ch rune) bool {
 }
 
 func (l *Lexer) readChar() {
+	if l.readPosition > 0 && l.position < len(l.input) {
+		l.offset += utf8.RuneLen(l.ch)
+		if l.ch == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+	}
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // EOF
 	} else {
@@ -152,28 +285,192 @@ func (l *Lexer) readChar() {
 	l.readPosition++
 }
 
-func (l *Lexer) readString() string {
+func (l *Lexer) currPosition() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.offset,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
+
+// readString читает строку в двойных кавычках, заменяя управляющие
+// последовательности \n, \t, \", \\ и \u{...} соответствующими символами.
+// Для незакрытой строки или неизвестной последовательности возвращается
+// токен ILLEGAL
+func (l *Lexer) readString() token.Token {
+	pos := l.currPosition()
+	position := l.position
+	valid := true
+
+	var out strings.Builder
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '"':
+			if !valid {
+				return token.Token{Type: token.ILLEGAL, Literal: string(l.input[position : l.position+1])}
+			}
+			return token.Token{Type: token.STRING_VAL, Literal: out.String()}
+		case 0, '\n':
+			l.error(pos, "незакрытая строка")
+			return token.Token{Type: token.ILLEGAL, Literal: string(l.input[position:l.position])}
+		case '\\':
+			ch, ok := l.readEscape()
+			if !ok {
+				valid = false
+				continue
+			}
+			out.WriteRune(ch)
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// readEscape читает управляющую последовательность, l.ch указывает на \
+func (l *Lexer) readEscape() (rune, bool) {
+	pos := l.currPosition()
+
+	switch l.peekRune() {
+	case 'n':
+		l.readChar()
+		return '\n', true
+	case 't':
+		l.readChar()
+		return '\t', true
+	case '"':
+		l.readChar()
+		return '"', true
+	case '\\':
+		l.readChar()
+		return '\\', true
+	case 'u':
+		l.readChar()
+		return l.readUnicodeEscape(pos)
+	case 0, '\n':
+		// о незакрытой строке сообщит readString
+		return 0, false
+	default:
+		l.readChar()
+		l.error(pos, "неизвестная управляющая последовательность \\"+string(l.ch))
+		return 0, false
+	}
+}
+
+// readUnicodeEscape читает \u{XXXX}, l.ch указывает на u
+func (l *Lexer) readUnicodeEscape(pos token.Position) (rune, bool) {
+	if l.peekRune() != '{' {
+		l.error(pos, "ожидалось \\u{...}")
+		return 0, false
+	}
+	l.readChar()
+
+	position := l.readPosition
+	for isHexDigit(l.peekRune()) {
+		l.readChar()
+	}
+	digits := string(l.input[position:l.readPosition])
+
+	if l.peekRune() != '}' {
+		l.error(pos, "ожидалось \\u{...}")
+		return 0, false
+	}
+	l.readChar()
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		l.error(pos, "неверный код символа \\u{"+digits+"}")
+		return 0, false
+	}
+
+	return rune(code), true
+}
+
+// readRawString читает строку в обратных кавычках как есть,
+// без управляющих последовательностей, строка может быть многострочной
+func (l *Lexer) readRawString() token.Token {
+	pos := l.currPosition()
 	position := l.position + 1
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		if l.ch == '`' {
+			return token.Token{Type: token.STRING_VAL, Literal: string(l.input[position:l.position])}
+		}
+		if l.ch == 0 {
+			l.error(pos, "незакрытая строка")
+			return token.Token{Type: token.ILLEGAL, Literal: string(l.input[position-1 : l.position])}
 		}
 	}
+}
 
-	return string(l.input[position:l.position])
+func isHexDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9' || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
 }
 
-func (l *Lexer) peekRune() byte {
-	if l.readPosition >= len(l.input) {
+func (l *Lexer) peekRune() rune {
+	return l.peekRuneAt(1)
+}
+
+// peekRuneAt возвращает символ, стоящий на n позиций после текущего
+func (l *Lexer) peekRuneAt(n int) rune {
+	if l.position+n >= len(l.input) {
 		return 0
-	} else {
-		return byte(l.input[l.readPosition])
 	}
+	return l.input[l.position+n]
 }
 
+// skipWhitespace пропускает пробелы и комментарии, текст комментариев
+// сохраняется и прикрепляется к следующему токену
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\r' || l.ch == '\n' {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\r' || l.ch == '\n':
+			l.readChar()
+		case l.ch == '/' && l.peekRune() == '/':
+			l.comments = append(l.comments, l.readLineComment())
+		case l.ch == '/' && l.peekRune() == '*':
+			l.comments = append(l.comments, l.readBlockComment())
+		default:
+			return
+		}
+	}
+}
+
+// readLineComment читает комментарий вида // ... до конца строки
+func (l *Lexer) readLineComment() string {
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+
+	return string(l.input[position:l.position])
+}
+
+// readBlockComment читает комментарий вида /* ... */, такие комментарии
+// могут быть вложены друг в друга
+func (l *Lexer) readBlockComment() string {
+	pos := l.currPosition()
+	position := l.position
+	depth := 0
+
+	for {
+		switch {
+		case l.ch == 0:
+			l.error(pos, "незакрытый комментарий")
+			return string(l.input[position:l.position])
+		case l.ch == '/' && l.peekRune() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekRune() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return string(l.input[position:l.position])
+			}
+		}
 		l.readChar()
 	}
 }
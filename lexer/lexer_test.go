@@ -7,7 +7,8 @@ import (
 )
 
 func TestNextToken(t *testing.T) {
-	input := `:= == != ;,+-*/
+	input := `:= == != ;,+-*/ && || и или не
+% ** += -= *= /= %=
 () { } 
 <=	> < >=
 true = "true" 
@@ -17,6 +18,8 @@ true = "true"
 истина
 цикл (;)
 [1, 2]
+x в 1..10
+...числа
 `
 
 	tests := []struct {
@@ -33,6 +36,18 @@ true = "true"
 		{token.MINUS, "-"},
 		{token.ASTERISK, "*"},
 		{token.SLASH, "/"},
+		{token.AND, "&&"},
+		{token.OR, "||"},
+		{token.AND, "и"},
+		{token.OR, "или"},
+		{token.BANG, "не"},
+		{token.PERCENT, "%"},
+		{token.POWER, "**"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.MINUS_ASSIGN, "-="},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.SLASH_ASSIGN, "/="},
+		{token.PERCENT_ASSIGN, "%="},
 		{token.LPAREN, "("},
 		{token.RPAREN, ")"},
 		{token.LBRACE, "{"},
@@ -66,6 +81,221 @@ true = "true"
 		{token.COMMA, ","},
 		{token.INT_VAL, "2"},
 		{token.RBRACKET, "]"},
+		{token.IDENT, "x"},
+		{token.IN, "в"},
+		{token.INT_VAL, "1"},
+		{token.DOTDOT, ".."},
+		{token.INT_VAL, "10"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "числа"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "создать x: число = 5;\n\tвывести(x);"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+		expectedOffset  int
+	}{
+		{"создать", 1, 1, 0},
+		{"x", 1, 9, 15},
+		{":", 1, 10, 16},
+		{"число", 1, 12, 18},
+		{"=", 1, 18, 29},
+		{"5", 1, 20, 31},
+		{";", 1, 21, 32},
+		{"вывести", 2, 2, 35},
+		{"(", 2, 9, 49},
+		{"x", 2, 10, 50},
+		{")", 2, 11, 51},
+		{";", 2, 12, 52},
+		{"", 2, 13, 53},
+	}
+
+	l := NewFile("test.um", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos.Line, tok.Pos.Column)
+		}
+		if tok.Pos.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] - offset wrong. expected=%d, got=%d",
+				i, tt.expectedOffset, tok.Pos.Offset)
+		}
+		if tok.Pos.Filename != "test.um" {
+			t.Fatalf("tests[%d] - filename wrong. got=%q", i, tok.Pos.Filename)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// первая строка
+создать x: число = 5; // после кода
+/* блочный
+   комментарий */ x / 2
+/* внешний /* вложенный */ еще внешний */ вывести`
+
+	tests := []struct {
+		expectedType     token.TokenType
+		expectedLiteral  string
+		expectedComments []string
+	}{
+		{token.LET, "создать", []string{"// первая строка"}},
+		{token.IDENT, "x", nil},
+		{token.COLON, ":", nil},
+		{token.INT, "число", nil},
+		{token.ASSIGN, "=", nil},
+		{token.INT_VAL, "5", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "x", []string{"// после кода", "/* блочный\n   комментарий */"}},
+		{token.SLASH, "/", nil},
+		{token.INT_VAL, "2", nil},
+		{token.IDENT, "вывести", []string{"/* внешний /* вложенный */ еще внешний */"}},
+		{token.EOF, "", nil},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if len(tok.Comments) != len(tt.expectedComments) {
+			t.Fatalf("tests[%d] - comments wrong. expected=%q, got=%q",
+				i, tt.expectedComments, tok.Comments)
+		}
+		for j, comment := range tt.expectedComments {
+			if tok.Comments[j] != comment {
+				t.Fatalf("tests[%d] - comment wrong. expected=%q, got=%q",
+					i, comment, tok.Comments[j])
+			}
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := New("x /* /* */ y")
+
+	var errors []string
+	l.SetErrorHandler(func(pos token.Position, msg string) {
+		errors = append(errors, pos.String()+": "+msg)
+	})
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+
+	if len(errors) != 1 || errors[0] != "1:3: незакрытый комментарий" {
+		t.Fatalf("wrong errors. got=%q", errors)
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedErrors  []string
+	}{
+		{`"а\nб"`, token.STRING_VAL, "а\nб", nil},
+		{`"\tтаб"`, token.STRING_VAL, "\tтаб", nil},
+		{`"скажи \"привет\""`, token.STRING_VAL, `скажи "привет"`, nil},
+		{`"C:\\папка"`, token.STRING_VAL, `C:\папка`, nil},
+		{`"\u{41}\u{1F600}"`, token.STRING_VAL, "A\U0001F600", nil},
+		{"`рамка\n|  |\n\\n`", token.STRING_VAL, "рамка\n|  |\n\\n", nil},
+		{`"плохо \q"`, token.ILLEGAL, `"плохо \q"`, []string{`1:8: неизвестная управляющая последовательность \q`}},
+		{`"\u{110000}"`, token.ILLEGAL, `"\u{110000}"`, []string{`1:2: неверный код символа \u{110000}`}},
+		{`"\u41"`, token.ILLEGAL, `"\u41"`, []string{`1:2: ожидалось \u{...}`}},
+		{`x = "без конца`, token.ILLEGAL, `"без конца`, []string{"1:5: незакрытая строка"}},
+		{"x = \"до\nконца строки\"", token.ILLEGAL, `"до`, []string{"1:5: незакрытая строка"}},
+		{"x = `без конца", token.ILLEGAL, "`без конца", []string{"1:5: незакрытая строка"}},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+
+		var errors []string
+		l.SetErrorHandler(func(pos token.Position, msg string) {
+			errors = append(errors, pos.String()+": "+msg)
+		})
+
+		tok := l.NextToken()
+		for tok.Type == token.IDENT || tok.Type == token.ASSIGN {
+			tok = l.NextToken()
+		}
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if len(errors) != len(tt.expectedErrors) {
+			t.Fatalf("tests[%d] - errors wrong. expected=%q, got=%q",
+				i, tt.expectedErrors, errors)
+		}
+		for j, err := range tt.expectedErrors {
+			if errors[j] != err {
+				t.Fatalf("tests[%d] - error wrong. expected=%q, got=%q",
+					i, err, errors[j])
+			}
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `5 3.14 0.5 1e10 2.5e-3 6E+2 7. x1 в_число 2e`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT_VAL, "5"},
+		{token.FLOAT_VAL, "3.14"},
+		{token.FLOAT_VAL, "0.5"},
+		{token.FLOAT_VAL, "1e10"},
+		{token.FLOAT_VAL, "2.5e-3"},
+		{token.FLOAT_VAL, "6E+2"},
+		{token.INT_VAL, "7"},
+		{token.ILLEGAL, "."},
+		{token.IDENT, "x1"},
+		{token.IDENT, "в_число"},
+		{token.INT_VAL, "2"},
+		{token.IDENT, "e"},
+		{token.EOF, ""},
 	}
 
 	l := New(input)
@@ -7,6 +7,7 @@ import (
 
 type Array struct {
 	Elements []Object
+	Frozen   bool // массив принадлежит постоянной и не может меняться
 }
 
 func (ao *Array) Type() ObjectType { return ArrayObj }
@@ -0,0 +1,16 @@
+package object
+
+import "math/big"
+
+// BigInteger хранит целое число, которое не помещается в int64
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInteger) Type() ObjectType {
+	return BigIntegerObj
+}
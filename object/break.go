@@ -0,0 +1,15 @@
+package object
+
+// Break поднимается по вложенным блокам до цикла с меткой Label,
+// пустая метка означает ближайший цикл
+type Break struct {
+	Label string
+}
+
+func (b *Break) Type() ObjectType {
+	return BreakObj
+}
+
+func (b *Break) Inspect() string {
+	return "прервать"
+}
@@ -0,0 +1,15 @@
+package object
+
+// Continue поднимается по вложенным блокам до цикла с меткой Label,
+// пустая метка означает ближайший цикл
+type Continue struct {
+	Label string
+}
+
+func (c *Continue) Type() ObjectType {
+	return ContinueObj
+}
+
+func (c *Continue) Inspect() string {
+	return "продолжить"
+}
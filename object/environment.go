@@ -1,16 +1,26 @@
 package object
 
+import "github.com/usamaroman/uman/token"
+
+// binding - значение переменной вместе с ее объявленным типом
+type binding struct {
+	value    Object
+	dataType token.TokenType // "" если тип не указан
+}
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]binding
+	consts map[string]bool
+	frame  *Frame // вызов функции, для которого создана область видимости
+	outer  *Environment
+
+	maxCallDepth int // предел глубины вызовов, 0 - не задан
 }
 
+// NewEnvironment создает пустую область видимости. Словари переменных
+// создаются при первом объявлении: большинство блоков ничего не объявляет
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
-	return &Environment{
-		store: s,
-		outer: nil,
-	}
+	return &Environment{}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
@@ -19,15 +29,116 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return environment
 }
 
+// SetFrame связывает область видимости с вызовом функции
+func (e *Environment) SetFrame(frame *Frame) {
+	e.frame = frame
+}
+
+// Frame возвращает вызов функции, внутри которого выполняется код,
+// или nil на верхнем уровне программы
+func (e *Environment) Frame() *Frame {
+	for env := e; env != nil; env = env.outer {
+		if env.frame != nil {
+			return env.frame
+		}
+	}
+	return nil
+}
+
+// SetMaxCallDepth задает наибольшее количество вложенных вызовов функций
+// для программы, выполняемой в e. 0 возвращает значение по умолчанию
+func (e *Environment) SetMaxCallDepth(depth int) {
+	e.maxCallDepth = depth
+}
+
+// MaxCallDepth возвращает предел глубины вызовов из ближайшей области
+// видимости, где он задан, или 0, если он нигде не задан
+func (e *Environment) MaxCallDepth() int {
+	for env := e; env != nil; env = env.outer {
+		if env.maxCallDepth > 0 {
+			return env.maxCallDepth
+		}
+	}
+	return 0
+}
+
 func (e *Environment) Set(name string, obj Object) Object {
-	e.store[name] = obj
+	if e.store == nil {
+		e.store = make(map[string]binding)
+	}
+	b := e.store[name]
+	b.value = obj
+	e.store[name] = b
+	return obj
+}
+
+// Declare создает переменную с объявленным типом dataType
+func (e *Environment) Declare(name string, obj Object, dataType token.TokenType) Object {
+	if e.store == nil {
+		e.store = make(map[string]binding)
+	}
+	e.store[name] = binding{value: obj, dataType: dataType}
+	return obj
+}
+
+// DeclareConst создает постоянную, значение которой нельзя изменить
+func (e *Environment) DeclareConst(name string, obj Object, dataType token.TokenType) Object {
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.Declare(name, obj, dataType)
+	e.consts[name] = true
 	return obj
 }
 
+// IsConst сообщает, является ли name постоянной в той области видимости,
+// где она объявлена
+func (e *Environment) IsConst(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.consts[name]
+	}
+	if e.outer != nil {
+		return e.outer.IsConst(name)
+	}
+	return false
+}
+
+// GetLocal ищет переменную только в текущей области видимости
+func (e *Environment) GetLocal(name string) (Object, bool) {
+	b, ok := e.store[name]
+	return b.value, ok
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
+	b, ok := e.store[name]
 	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+		return e.outer.Get(name)
+	}
+	return b.value, ok
+}
+
+// DataType возвращает объявленный тип переменной. У переменных, созданных
+// без типа (например, аргументов функций), его нет
+func (e *Environment) DataType(name string) (token.TokenType, bool) {
+	if b, ok := e.store[name]; ok {
+		return b.dataType, b.dataType != ""
+	}
+	if e.outer != nil {
+		return e.outer.DataType(name)
+	}
+	return "", false
+}
+
+// Assign меняет значение переменной в ближайшей области видимости,
+// где она объявлена. Возвращает false, если переменной нет
+func (e *Environment) Assign(name string, obj Object) (Object, bool) {
+	if b, ok := e.store[name]; ok {
+		b.value = obj
+		e.store[name] = b
+		return obj, true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, obj)
 	}
-	return obj, ok
+	return nil, false
 }
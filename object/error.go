@@ -1,7 +1,11 @@
 package object
 
+import "github.com/usamaroman/uman/token"
+
 type Error struct {
 	Message string
+	Pos     token.Position // место в исходном тексте, где возникла ошибка
+	Trace   []*Frame       // стек вызовов в момент ошибки, последний вызов первым
 }
 
 func (e *Error) Type() ObjectType {
@@ -9,5 +13,8 @@ func (e *Error) Type() ObjectType {
 }
 
 func (e *Error) Inspect() string {
+	if e.Pos.IsValid() {
+		return "ERROR " + e.Pos.String() + ": " + e.Message
+	}
 	return "ERROR " + e.Message
 }
@@ -0,0 +1,165 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// Frame описывает вызов функции в стеке вызовов
+type Frame struct {
+	Fn       *Function      // вызванная функция
+	Function string         // имя функции или "функция", если имени нет
+	Pos      token.Position // место вызова
+	Args     []Object       // аргументы в момент вызова, см. SnapshotArgs
+	Caller   *Frame         // вызов, из которого была вызвана функция
+	Depth    int            // количество вызовов в стеке, включая этот
+	// TailCalls - сколько вызовов функции самой себя в конце тела было
+	// выполнено на месте этого вызова. Args и Pos относятся к последнему из них
+	TailCalls int
+}
+
+// String записывает вызов в виде "сумма(1, 2) в test.um:3:5". Если вызов
+// заменил предыдущие хвостовые вызовы, добавляется их количество
+func (f *Frame) String() string {
+	args := make([]string, 0, len(f.Args))
+	for _, arg := range f.Args {
+		args = append(args, Summarize(arg))
+	}
+
+	str := f.Function + "(" + strings.Join(args, ", ") + ") в " + f.Pos.String()
+	if f.TailCalls > 0 {
+		str += fmt.Sprintf(" (хвостовых вызовов: %d)", f.TailCalls)
+	}
+	return str
+}
+
+// maxFrameArg - наибольшая длина записи одного аргумента
+const maxFrameArg = 20
+
+// maxSummaryBits - числа длиннее этого записываются только количеством цифр:
+// перевод большого числа в десятичную запись дорогой
+const maxSummaryBits = 256
+
+// snapshot - краткая запись массива или словаря, сделанная в момент вызова:
+// сами они могут измениться до того, как стек будет напечатан
+type snapshot string
+
+func (s snapshot) Type() ObjectType { return "SNAPSHOT" }
+func (s snapshot) Inspect() string  { return string(s) }
+
+// SnapshotArgs готовит аргументы вызова для записи в стек. Неизменяемые
+// значения сохраняются как есть и записываются только при печати стека,
+// а массивы и словари сразу заменяются краткой записью
+func SnapshotArgs(args []Object) []Object {
+	result := make([]Object, len(args))
+	for i, arg := range args {
+		switch arg.(type) {
+		case *Array, *Hash:
+			result[i] = snapshot(Summarize(arg))
+		default:
+			result[i] = arg
+		}
+	}
+	return result
+}
+
+// Summarize возвращает краткую запись obj для стека вызовов. Большие массивы
+// и словари не обходятся целиком: запись обрывается на maxFrameArg символах
+func Summarize(obj Object) string {
+	s := &summary{left: maxFrameArg + 1}
+	s.object(obj)
+
+	str := []rune(s.out.String())
+	if len(str) > maxFrameArg {
+		str = append(str[:maxFrameArg-3], []rune("...")...)
+	}
+	return string(str)
+}
+
+// summary собирает запись аргумента, пока не закончится место
+type summary struct {
+	out  strings.Builder
+	left int
+}
+
+// write добавляет str и возвращает false, если место закончилось
+func (s *summary) write(str string) bool {
+	for _, r := range str {
+		if s.left == 0 {
+			return false
+		}
+		if r == '\n' {
+			r = ' '
+		}
+		s.out.WriteRune(r)
+		s.left--
+	}
+	return s.left > 0
+}
+
+func (s *summary) object(obj Object) bool {
+	switch obj := obj.(type) {
+	case nil, *Null:
+		return s.write("пусто")
+	case *Array:
+		if !s.write("[") {
+			return false
+		}
+		for i, el := range obj.Elements {
+			if i > 0 && !s.write(", ") {
+				return false
+			}
+			if !s.object(el) {
+				return false
+			}
+		}
+		return s.write("]")
+	case *Hash:
+		if !s.write("{") {
+			return false
+		}
+		for i, key := range obj.keys {
+			if i > 0 && !s.write(", ") {
+				return false
+			}
+			if str, ok := key.(*String); ok {
+				if !s.write(strconv.Quote(str.Value)) {
+					return false
+				}
+			} else if !s.object(key) {
+				return false
+			}
+			value, _ := obj.Get(key)
+			if !s.write(": ") || !s.object(value) {
+				return false
+			}
+		}
+		return s.write("}")
+	case *BigInteger:
+		if bits := obj.Value.BitLen(); bits > maxSummaryBits {
+			// в числе из bits двоичных разрядов примерно bits*log10(2) цифр
+			return s.write(fmt.Sprintf("число из ~%d цифр", int(float64(bits)*math.Log10(2))+1))
+		}
+		return s.write(obj.Inspect())
+	case *Function:
+		if obj.Name == "" {
+			return s.write("функция")
+		}
+		return s.write("функция " + obj.Name)
+	default:
+		return s.write(obj.Inspect())
+	}
+}
+
+// Stack возвращает не больше limit последних вызовов стека, начиная с f
+func (f *Frame) Stack(limit int) []*Frame {
+	stack := make([]*Frame, 0)
+	for frame := f; frame != nil && len(stack) < limit; frame = frame.Caller {
+		stack = append(stack, frame)
+	}
+	return stack
+}
@@ -2,15 +2,20 @@ package object
 
 import (
 	"bytes"
-	"strings"
 
 	"github.com/usamaroman/uman/ast"
+	"github.com/usamaroman/uman/token"
 )
 
 type Function struct {
-	Arguments []*ast.Identifier
-	Body      *ast.BlockStatement
-	Env       *Environment
+	Name          string // имя, под которым функция была создана, может отсутствовать
+	Arguments     []*ast.Identifier
+	ArgumentTypes []token.TokenType
+	Defaults      []ast.Expression
+	Variadic      bool
+	ReturnType    token.TokenType
+	Body          *ast.BlockStatement
+	Env           *Environment
 }
 
 func (f *Function) Type() ObjectType {
@@ -20,15 +25,12 @@ func (f *Function) Type() ObjectType {
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
-	args := make([]string, 0)
-	for _, arg := range f.Arguments {
-		args = append(args, arg.String())
-	}
-
 	out.WriteString("функция")
-	out.WriteString("(")
-	out.WriteString(strings.Join(args, ", "))
-	out.WriteString(") {\n")
+	if f.Name != "" {
+		out.WriteString(" " + f.Name)
+	}
+	out.WriteString(ast.Signature(f.Arguments, f.ArgumentTypes, f.Defaults, f.Variadic, f.ReturnType))
+	out.WriteString(" {\n")
 	out.WriteString(f.Body.String())
 	out.WriteString("\n}")
 
@@ -0,0 +1,164 @@
+package object
+
+import (
+	"bytes"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// HashKey однозначно определяет ключ словаря
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable реализуют объекты, которые могут быть ключами словаря
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *BigInteger) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// sameKey сравнивает ключи по значению: у разных строк HashKey может совпасть
+func sameKey(a, b Hashable) bool {
+	switch a := a.(type) {
+	case *Integer:
+		b, ok := b.(*Integer)
+		return ok && a.Value == b.Value
+	case *BigInteger:
+		b, ok := b.(*BigInteger)
+		return ok && a.Value.Cmp(b.Value) == 0
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	default:
+		return false
+	}
+}
+
+type HashPair struct {
+	Key   Hashable
+	Value Object
+}
+
+// Hash хранит пары ключ-значение в порядке их добавления
+type Hash struct {
+	Frozen  bool                   // словарь принадлежит постоянной и не может меняться
+	buckets map[HashKey][]HashPair // пары с одинаковым HashKey
+	keys    []Hashable             // ключи в порядке добавления
+}
+
+func NewHash() *Hash {
+	return &Hash{buckets: make(map[HashKey][]HashPair)}
+}
+
+// Len возвращает количество пар
+func (h *Hash) Len() int {
+	return len(h.keys)
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	for _, pair := range h.buckets[key.HashKey()] {
+		if sameKey(pair.Key, key) {
+			return pair.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	bucket := h.buckets[hashKey]
+	for i, pair := range bucket {
+		if sameKey(pair.Key, key) {
+			bucket[i].Value = value
+			return
+		}
+	}
+
+	h.buckets[hashKey] = append(bucket, HashPair{Key: key, Value: value})
+	h.keys = append(h.keys, key)
+}
+
+func (h *Hash) Delete(key Hashable) (Object, bool) {
+	hashKey := key.HashKey()
+	bucket := h.buckets[hashKey]
+	for i, pair := range bucket {
+		if !sameKey(pair.Key, key) {
+			continue
+		}
+
+		if len(bucket) == 1 {
+			delete(h.buckets, hashKey)
+		} else {
+			h.buckets[hashKey] = append(bucket[:i:i], bucket[i+1:]...)
+		}
+		for j, k := range h.keys {
+			if sameKey(k, key) {
+				h.keys = append(h.keys[:j], h.keys[j+1:]...)
+				break
+			}
+		}
+		return pair.Value, true
+	}
+
+	return nil, false
+}
+
+// Ordered возвращает пары в порядке добавления ключей
+func (h *Hash) Ordered() []HashPair {
+	pairs := make([]HashPair, 0, len(h.keys))
+	for _, k := range h.keys {
+		value, _ := h.Get(k)
+		pairs = append(pairs, HashPair{Key: k, Value: value})
+	}
+	return pairs
+}
+
+func (h *Hash) Type() ObjectType { return HashObj }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := make([]string, 0)
+	for _, pair := range h.Ordered() {
+		key := pair.Key.Inspect()
+		// строковые ключи в кавычках, чтобы {"1": 1} отличался от {1: 1}
+		if str, ok := pair.Key.(*String); ok {
+			key = strconv.Quote(str.Value)
+		}
+		pairs = append(pairs, key+": "+pair.Value.Inspect())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
@@ -6,14 +6,21 @@ type BuiltinFunction func(args ...Object) Object
 
 const (
 	IntegerObj     = "INTEGER"
+	BigIntegerObj  = "BIG_INTEGER"
+	FloatObj       = "FLOAT"
 	BooleanObj     = "BOOLEAN"
 	StringObj      = "STRING"
 	NullObj        = "NULL"
 	ReturnValueObj = "RETURN_VALUE"
+	BreakObj       = "BREAK"
+	ContinueObj    = "CONTINUE"
+	TailCallObj    = "TAIL_CALL"
 	ErrorObj       = "ERROR"
 	FunctionObj    = "FUNCTION"
 	BuiltinObj     = "BUILTIN"
 	ArrayObj       = "ARRAY"
+	HashObj        = "HASH"
+	RangeObj       = "RANGE"
 )
 
 type Object interface {
@@ -0,0 +1,21 @@
+package object
+
+import "fmt"
+
+// Range описывает числа от Start до End включительно с шагом Step
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType {
+	return RangeObj
+}
+
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("%d..%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("от(%d, %d, %d)", r.Start, r.End, r.Step)
+}
@@ -4,10 +4,10 @@ type String struct {
 	Value string
 }
 
-func (s String) Inspect() string {
+func (s *String) Inspect() string {
 	return s.Value
 }
 
-func (s String) Type() ObjectType {
+func (s *String) Type() ObjectType {
 	return StringObj
 }
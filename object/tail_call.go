@@ -0,0 +1,26 @@
+package object
+
+import (
+	"strings"
+
+	"github.com/usamaroman/uman/token"
+)
+
+// TailCall - вызов функцией самой себя в инструкции вернуть. Вместо нового
+// вызова applyFunction повторяет тело функции с аргументами Args
+type TailCall struct {
+	Args []Object
+	Pos  token.Position // место вызова
+}
+
+func (t *TailCall) Type() ObjectType {
+	return TailCallObj
+}
+
+func (t *TailCall) Inspect() string {
+	args := make([]string, 0, len(t.Args))
+	for _, arg := range t.Args {
+		args = append(args, arg.Inspect())
+	}
+	return "вызов(" + strings.Join(args, ", ") + ")"
+}
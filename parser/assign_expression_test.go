@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/usamaroman/uman/ast"
+)
+
+func TestParsingAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		target   string
+		operator string
+		value    interface{}
+	}{
+		{"x = 5;", "x", "=", 5},
+		{"x += 5;", "x", "+=", 5},
+		{"x -= 5;", "x", "-=", 5},
+		{"x *= 5;", "x", "*=", 5},
+		{"x /= 5;", "x", "/=", 5},
+		{"x %= 5;", "x", "%=", 5},
+		{"мас[0] = 5;", "(мас[0])", "=", 5},
+		{"мас[i] += 5;", "(мас[i])", "+=", 5},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("exp is not ast.AssignExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Target.String() != tt.target {
+			t.Errorf("exp.Target is not %q. got=%q", tt.target, exp.Target.String())
+		}
+		if exp.Operator != tt.operator {
+			t.Errorf("exp.Operator is not %q. got=%q", tt.operator, exp.Operator)
+		}
+		testLiteralExpression(t, exp.Value, tt.value)
+	}
+}
+
+func TestAssignExpressionIsRightAssociative(t *testing.T) {
+	p := New("a = b = 1;")
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if program.String() != "(a = (b = 1))" {
+		t.Errorf("wrong program. got=%q", program.String())
+	}
+}
+
+func TestInvalidAssignTargets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 = 2;", "1:1: нельзя присваивать значение выражению 1"},
+		{"1 += 1;", "1:1: нельзя присваивать значение выражению 1"},
+		{"a + b = 3;", "1:3: нельзя присваивать значение выражению (a + b)"},
+		{"f() = 3;", "1:1: нельзя присваивать значение выражению f()"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
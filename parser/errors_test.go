@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParserErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"создать x: число = ;", "test.um:1:20: no prefix parse function for ; found"},
+		{"создать x число = 1;", "test.um:1:11: expected next token to be :, got INT instead"},
+		{"вывести(1);\nсоздать x: = 1;", "test.um:2:12: missing data type"},
+		{"создать и: булев = истина;", `test.um:1:9: "и" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"вывести(или);", `test.um:1:9: "или" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"функция(x, и) { x };", `test.um:1:12: "и" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"функция(...не) { 1 };", `test.um:1:12: "не" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"функция или() { 1 }", `test.um:1:9: "или" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"цикл (и в [1, 2]) { }", `test.um:1:7: "и" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"цикл (x, или в [1, 2]) { }", `test.um:1:10: "или" теперь логический оператор и не может быть именем переменной, переименуйте переменную`},
+		{"создать в: число = 1;", `test.um:1:9: "в" теперь ключевое слово и не может быть именем переменной, переименуйте переменную`},
+		{"вывести(в + 1);", `test.um:1:9: "в" теперь ключевое слово и не может быть именем переменной, переименуйте переменную`},
+		{"функция(в) { 1 };", `test.um:1:9: "в" теперь ключевое слово и не может быть именем переменной, переименуйте переменную`},
+		{"цикл (в в [1, 2]) { }", `test.um:1:7: "в" теперь ключевое слово и не может быть именем переменной, переименуйте переменную`},
+	}
+
+	for _, tt := range tests {
+		p := NewFile("test.um", tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestFormerIdentErrorSkipsStatement(t *testing.T) {
+	// после ошибки о слове, ставшем ключевым, остаток инструкции пропускается:
+	// лишних ошибок нет, а следующая инструкция разбирается как обычно
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"создать и: число = 1;", []string{"1:9"}},
+		{"создать и: число = 1; создать = 2;", []string{"1:9", "1:33"}},
+		{"вывести(в + 1); создать = 2;", []string{"1:9", "1:27"}},
+		{"функция f(x, или) { вернуть x; } вывести(1);", []string{"1:14"}},
+		{"создать f: функция = функция(x, не) { x }; создать = 2;", []string{"1:33", "1:54"}},
+		{"цикл (и в [1, 2]) { вывести(и); } вывести(1);", []string{"1:7"}},
+		{"если (истина) { создать в: число = 1; создать = 2; } иначе { 3 }", []string{"1:25", "1:49"}},
+		{"выбор (1) { случай 1: создать или: число = 1; по_умолчанию: 2 }", []string{"1:31"}},
+		{"функция f() { вывести(в) } f();", []string{"1:23"}},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.ErrorList()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("wrong number of errors for %q. expected=%d, got=%q",
+				tt.input, len(tt.expected), p.Errors())
+			continue
+		}
+		if !strings.Contains(errors[0].Message, "теперь") {
+			t.Errorf("wrong first error for %q: %q", tt.input, errors[0].Message)
+		}
+		for i, err := range errors {
+			if err.Pos.String() != tt.expected[i] {
+				t.Errorf("wrong position of error %d for %q. expected=%s, got=%s",
+					i, tt.input, tt.expected[i], err.Pos.String())
+			}
+		}
+	}
+}
+
+func TestLexerErrorsAreReported(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"создать x: число = 1; /* без конца", "1:23: незакрытый комментарий"},
+		{"x @ 1;", `1:3: неизвестный символ "@"`},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("wrong number of errors for %q. got=%q", tt.input, errors)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
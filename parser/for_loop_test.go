@@ -83,6 +83,144 @@ func TestForLoopExpressionEvaluator(t *testing.T) {
 	}
 }
 
+func TestForEachExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		index    string
+		value    string
+		iterable string
+	}{
+		{"цикл (x в мас) { вывести(x); }", "", "x", "мас"},
+		{"цикл (i, x в мас) { вывести(x); }", "i", "x", "мас"},
+		{"цикл (n в 1..10) { вывести(n); }", "", "n", "(1 .. 10)"},
+		{"цикл (n в от(0, 10, 2)) { вывести(n); }", "", "n", "от(0, 10, 2)"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.ForEachExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.ForEachExpression. got=%T", stmt.Expression)
+		}
+
+		if tt.index == "" {
+			if exp.Index != nil {
+				t.Errorf("exp.Index is not nil. got=%s", exp.Index)
+			}
+		} else {
+			testIdentifier(t, exp.Index, tt.index)
+		}
+		testIdentifier(t, exp.Value, tt.value)
+
+		if exp.Iterable.String() != tt.iterable {
+			t.Errorf("exp.Iterable is not %q. got=%q", tt.iterable, exp.Iterable.String())
+		}
+		if len(exp.Statement.Statements) != 1 {
+			t.Errorf("body is not 1 statement. got=%d", len(exp.Statement.Statements))
+		}
+	}
+}
+
+func TestThreePartForLoopExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"цикл (создать i: число = 0; i < 10; i += 1) { вывести(i); }", "цикл (создать i: число = 0; (i < 10); (i += 1))вывести(i)"},
+		{"цикл (i = 0; i < 10; i += 1) { }", "цикл ((i = 0); (i < 10); (i += 1))"},
+		{"цикл (; i < 10;) { }", "цикл ((i < 10))"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.ForLoopExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.ForLoopExpression. got=%T", stmt.Expression)
+		}
+		if exp.String() != tt.expected {
+			t.Errorf("wrong loop. expected=%q, got=%q", tt.expected, exp.String())
+		}
+	}
+}
+
+func TestBreakAndContinueStatements(t *testing.T) {
+	input := `
+внешний: цикл (истина) {
+    цикл (истина) {
+        прервать;
+        продолжить внешний;
+    }
+}
+`
+
+	p := New(input)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	outer, ok := stmt.Expression.(*ast.ForLoopExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.ForLoopExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, outer.Label, "внешний") {
+		return
+	}
+
+	inner := outer.Statement.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ForLoopExpression)
+	if inner.Label != nil {
+		t.Errorf("inner.Label is not nil. got=%s", inner.Label)
+	}
+
+	brk, ok := inner.Statement.Statements[0].(*ast.BreakStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.BreakStatement. got=%T", inner.Statement.Statements[0])
+	}
+	if brk.Label != nil {
+		t.Errorf("brk.Label is not nil. got=%s", brk.Label)
+	}
+
+	cont, ok := inner.Statement.Statements[1].(*ast.ContinueStatement)
+	if !ok {
+		t.Fatalf("Statements[1] is not ast.ContinueStatement. got=%T", inner.Statement.Statements[1])
+	}
+	testIdentifier(t, cont.Label, "внешний")
+}
+
+func TestBreakAndContinueErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"прервать;", "1:1: прервать вне цикла"},
+		{"если (истина) { продолжить; }", "1:17: продолжить вне цикла"},
+		{"цикл (истина) { создать ф: функция = функция() { прервать; }; }", "1:50: прервать вне цикла"},
+		{"цикл (истина) { прервать внешний; }", "1:26: нет цикла с меткой внешний"},
+		{"м: цикл (истина) { м: цикл (истина) {} }", "1:20: метка м уже используется"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
 func testStmt(t *testing.T, exp ast.Expression, value string, arg string) bool {
 	fn, ok := exp.(*ast.CallExpression)
 	if !ok {
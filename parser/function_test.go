@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/usamaroman/uman/ast"
+	"github.com/usamaroman/uman/token"
 )
 
 func TestFunctionExpression(t *testing.T) {
@@ -76,3 +77,168 @@ func TestFunctionParameterParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestTypedFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedTypes []token.TokenType
+		expectedRet   token.TokenType
+		expected      string
+	}{
+		{"функция(x: число, y: строка): булев { истина };", []token.TokenType{token.INT, token.STRING}, token.BOOL, "функция(x: число, y: строка): булев истина"},
+		{"функция(x, y: дробь) { x };", []token.TokenType{"", token.FLOAT}, "", "функция(x, y: дробь) x"},
+		{"функция(): массив { [] };", []token.TokenType{}, token.ARRAY, "функция(): массив []"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.ArgumentTypes) != len(tt.expectedTypes) {
+			t.Fatalf("wrong number of argument types. want %d, got=%d",
+				len(tt.expectedTypes), len(function.ArgumentTypes))
+		}
+		for i, dataType := range tt.expectedTypes {
+			if function.ArgumentTypes[i] != dataType {
+				t.Errorf("argument %d type wrong. want %q, got=%q", i, dataType, function.ArgumentTypes[i])
+			}
+		}
+		if function.ReturnType != tt.expectedRet {
+			t.Errorf("return type wrong. want %q, got=%q", tt.expectedRet, function.ReturnType)
+		}
+		if function.String() != tt.expected {
+			t.Errorf("wrong string. want %q, got=%q", tt.expected, function.String())
+		}
+	}
+}
+
+func TestTypedFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"функция(x: ) { x };", "1:12: missing data type"},
+		{"функция(x): y { x };", "1:13: missing data type"},
+		{"функция(1) { 1 };", "1:9: expected next token to be IDENT, got INT_VAL instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestDefaultAndVariadicParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		variadic bool
+		expected string
+	}{
+		{"функция(x, y = 10) { x };", false, "функция(x, y = 10) x"},
+		{"функция(x: число = 1 + 1) { x };", false, "функция(x: число = (1 + 1)) x"},
+		{"функция(...числа) { числа };", true, "функция(...числа) числа"},
+		{"функция(x, y = 2, ...z: число) { x };", true, "функция(x, y = 2, ...z: число) x"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if function.Variadic != tt.variadic {
+			t.Errorf("function.Variadic wrong. want %t, got=%t", tt.variadic, function.Variadic)
+		}
+		if len(function.Defaults) != len(function.Arguments) {
+			t.Errorf("function.Defaults has wrong length. want %d, got=%d",
+				len(function.Arguments), len(function.Defaults))
+		}
+		if function.String() != tt.expected {
+			t.Errorf("wrong string. want %q, got=%q", tt.expected, function.String())
+		}
+	}
+}
+
+func TestDefaultAndVariadicParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"функция(x = 1, y) { x };", "1:16: аргумент y без значения по умолчанию не может идти после аргументов со значением"},
+		{"функция(...x, y) { x };", "1:15: аргумент с ... должен быть последним"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestFunctionDeclaration(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		expected string
+	}{
+		{"функция сумма(x, y) { x + y }", "сумма", "функция сумма(x, y) (x + y)"},
+		{"функция ф(x: число = 1): число { x };", "ф", "функция ф(x: число = 1): число x"},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		decl, ok := program.Statements[0].(*ast.FunctionDeclaration)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not *ast.FunctionDeclaration. got=%T", program.Statements[0])
+		}
+		if decl.Name.Value != tt.name {
+			t.Errorf("decl.Name wrong. want %q, got=%q", tt.name, decl.Name.Value)
+		}
+		if decl.String() != tt.expected {
+			t.Errorf("wrong string. want %q, got=%q", tt.expected, decl.String())
+		}
+	}
+
+	// функция без имени остается выражением
+	p := New("функция(x) { x }(1);")
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	if _, ok := stmt.Expression.(*ast.CallExpression); !ok {
+		t.Fatalf("stmt.Expression is not *ast.CallExpression. got=%T", stmt.Expression)
+	}
+}
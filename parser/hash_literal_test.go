@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/usamaroman/uman/ast"
+)
+
+func TestParsingHashLiterals(t *testing.T) {
+	input := `{"один": 1, "два": 2 * 2, 3: истина}`
+
+	p := New(input)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	if !testStringVar(t, hash.Pairs[0].Key, "один") {
+		return
+	}
+	testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+	if !testStringVar(t, hash.Pairs[1].Key, "два") {
+		return
+	}
+	testInfixExpression(t, hash.Pairs[1].Value, 2, "*", 2)
+	testIntegerLiteral(t, hash.Pairs[2].Key, 3)
+	testBooleanLiteral(t, hash.Pairs[2].Value, true)
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	p := New("создать д: словарь = {};")
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.VariableStatement)
+	hash, ok := stmt.Value.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Value)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingHashLiteralErrors(t *testing.T) {
+	tests := []string{
+		`{"a" 1}`,
+		`{"a": 1 "b": 2}`,
+		`{"a": 1,`,
+	}
+
+	for _, input := range tests {
+		p := New(input)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
@@ -100,3 +100,35 @@ func TestIfElseExpression(t *testing.T) {
 	}
 
 }
+
+func TestElseIfExpression(t *testing.T) {
+	input := `если (x < 0) { -1 } иначе если (x == 0) { 0 } иначе { 1 }`
+
+	p := New(input)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if exp.Alternative != nil {
+		t.Errorf("exp.Alternative was not nil. got=%+v", exp.Alternative)
+	}
+	if exp.ElseIf == nil {
+		t.Fatalf("exp.ElseIf is nil")
+	}
+	if !testInfixExpression(t, exp.ElseIf.Condition, "x", "==", 0) {
+		return
+	}
+	if exp.ElseIf.Alternative == nil {
+		t.Fatalf("exp.ElseIf.Alternative is nil")
+	}
+
+	expected := "если (x < 0) (-1) иначе если (x == 0) 0 иначе 1"
+	if exp.String() != expected {
+		t.Errorf("wrong string. expected=%q, got=%q", expected, exp.String())
+	}
+}
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/usamaroman/uman/ast"
@@ -17,29 +19,44 @@ type (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // =
+	OR          // || или
+	AND         // && и
 	EQUALS      // ==
 	LESSGREATER // > or <
+	RANGE       // ..
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X !X
+	POWER       // **
 	CALL
 	INDEX // func(X)
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQUALS:   EQUALS,
-	token.NEQ:      EQUALS,
-	token.ASSIGN:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.EGT:      LESSGREATER,
-	token.ELT:      LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.EQUALS:          EQUALS,
+	token.NEQ:             EQUALS,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.PERCENT_ASSIGN:  ASSIGN,
+	token.OR:              OR,
+	token.AND:             AND,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.EGT:             LESSGREATER,
+	token.ELT:             LESSGREATER,
+	token.DOTDOT:          RANGE,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.POWER:           POWER,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
 }
 
 // Возвращает приоритет следующего токена
@@ -60,29 +77,56 @@ func (p *Parser) currPrecedences() int {
 	return LOWEST
 }
 
+// Error описывает ошибку разбора вместе с позицией в исходном тексте
+type Error struct {
+	Pos     token.Position
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
+
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []*Error
 
 	currToken token.Token
 	peekToken token.Token
 
+	// метки циклов, внутри которых находится парсер, "" для цикла без метки
+	loops []string
+
+	// recovering - в текущей инструкции уже найдена ошибка, после которой
+	// остальные ошибки инструкции только запутают, см. skipStatement
+	recovering bool
+
 	prefixParserFns map[token.TokenType]prefixParseFn // !test
 	infixParserFns  map[token.TokenType]infixParseFn  // test + test
 }
 
 func New(input string) *Parser {
-	l := lexer.New(input)
+	return NewFile("", input)
+}
+
+// NewFile создает парсер для исходного текста из файла filename,
+// имя файла попадает в позиции токенов и в сообщения об ошибках
+func NewFile(filename, input string) *Parser {
+	l := lexer.NewFile(filename, input)
 
 	p := &Parser{
 		l:               l,
-		errors:          make([]string, 0),
+		errors:          make([]*Error, 0),
 		prefixParserFns: make(map[token.TokenType]prefixParseFn),
 		infixParserFns:  make(map[token.TokenType]infixParseFn),
 	}
 
+	l.SetErrorHandler(p.addErrorAt)
+
+	p.registerPrefixFn(token.ILLEGAL, p.parseIllegal)
 	p.registerPrefixFn(token.IDENT, p.parseIdent)
 	p.registerPrefixFn(token.INT_VAL, p.parseIntegerLiteral)
+	p.registerPrefixFn(token.FLOAT_VAL, p.parseFloatLiteral)
 	p.registerPrefixFn(token.STRING_VAL, p.parseStringLiteral)
 	p.registerPrefixFn(token.TRUE, p.parseBoolean)
 	p.registerPrefixFn(token.FALSE, p.parseBoolean)
@@ -90,21 +134,33 @@ func New(input string) *Parser {
 	p.registerPrefixFn(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefixFn(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefixFn(token.IF, p.parseIfExpression)
+	p.registerPrefixFn(token.SWITCH, p.parseSwitchExpression)
 	p.registerPrefixFn(token.FOR, p.parseForLoopExpression)
 	p.registerPrefixFn(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefixFn(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefixFn(token.LBRACE, p.parseHashLiteral)
 
-	p.registerInfixFn(token.ASSIGN, p.parseInfixExpression)
+	p.registerInfixFn(token.ASSIGN, p.parseAssignExpression)
 	p.registerInfixFn(token.PLUS, p.parseInfixExpression)
 	p.registerInfixFn(token.MINUS, p.parseInfixExpression)
 	p.registerInfixFn(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfixFn(token.SLASH, p.parseInfixExpression)
+	p.registerInfixFn(token.PERCENT, p.parseInfixExpression)
+	p.registerInfixFn(token.POWER, p.parseInfixExpression)
+	p.registerInfixFn(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfixFn(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfixFn(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfixFn(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfixFn(token.PERCENT_ASSIGN, p.parseAssignExpression)
 	p.registerInfixFn(token.LT, p.parseInfixExpression)
 	p.registerInfixFn(token.ELT, p.parseInfixExpression)
 	p.registerInfixFn(token.GT, p.parseInfixExpression)
 	p.registerInfixFn(token.EGT, p.parseInfixExpression)
 	p.registerInfixFn(token.EQUALS, p.parseInfixExpression)
 	p.registerInfixFn(token.NEQ, p.parseInfixExpression)
+	p.registerInfixFn(token.AND, p.parseInfixExpression)
+	p.registerInfixFn(token.OR, p.parseInfixExpression)
+	p.registerInfixFn(token.DOTDOT, p.parseInfixExpression)
 	p.registerInfixFn(token.LPAREN, p.parseCallExpression)
 	p.registerInfixFn(token.LBRACKET, p.parseIndexExpression)
 
@@ -131,6 +187,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
+		p.skipStatement()
 		p.nextToken()
 	}
 
@@ -139,10 +196,24 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseVariableStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) || isFormerIdent(p.peekToken) {
+			return p.parseFunctionDeclaration()
+		}
+		return p.parseExpressionStatement()
+	case token.IDENT:
+		if p.peekTokenIs(token.COLON) {
+			return p.parseLabeledStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -155,6 +226,11 @@ func (p *Parser) parseVariableStatement() *ast.VariableStatement {
 
 	p.nextToken()
 
+	if isFormerIdent(p.currToken) {
+		p.formerIdentError(p.currToken)
+		return nil
+	}
+
 	stmt.Ident = &ast.Identifier{
 		Token: p.currToken,
 		Value: p.currToken.Literal,
@@ -164,11 +240,8 @@ func (p *Parser) parseVariableStatement() *ast.VariableStatement {
 		return nil
 	}
 
-	if p.getDataType() != token.STRING && p.getDataType() != token.INT && p.getDataType() != token.BOOL && p.getDataType() != token.FUNCTION && p.getDataType() != token.ARRAY {
-		p.addError("missing data type")
+	if !p.parseTypeAnnotation() {
 		return nil
-	} else {
-		p.nextToken()
 	}
 
 	stmt.DataType = p.currToken.Type
@@ -202,6 +275,99 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{
+		Token: p.currToken,
+	}
+
+	label, ok := p.parseLoopLabel()
+	if !ok {
+		return nil
+	}
+	stmt.Label = label
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{
+		Token: p.currToken,
+	}
+
+	label, ok := p.parseLoopLabel()
+	if !ok {
+		return nil
+	}
+	stmt.Label = label
+
+	return stmt
+}
+
+// parseLoopLabel читает необязательную метку после прервать/продолжить
+// и проверяет, что оператор стоит внутри подходящего цикла
+func (p *Parser) parseLoopLabel() (*ast.Identifier, bool) {
+	keyword := p.currToken
+
+	var label *ast.Identifier
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		label = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if len(p.loops) == 0 {
+		p.addErrorAt(keyword.Pos, fmt.Sprintf("%s вне цикла", keyword.Literal))
+		return nil, false
+	}
+	if label != nil && !p.inLoop(label.Value) {
+		p.addErrorAt(label.Pos(), fmt.Sprintf("нет цикла с меткой %s", label.Value))
+		return nil, false
+	}
+
+	return label, true
+}
+
+func (p *Parser) inLoop(label string) bool {
+	for _, l := range p.loops {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLabeledStatement разбирает цикл с меткой: метка: цикл (...) { ... }
+func (p *Parser) parseLabeledStatement() ast.Statement {
+	stmt := &ast.ExpressionStatement{
+		Token: p.currToken,
+	}
+	label := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	if p.inLoop(label.Value) {
+		p.addErrorAt(label.Pos(), fmt.Sprintf("метка %s уже используется", label.Value))
+		return nil
+	}
+
+	p.nextToken()
+	if !p.expectPeek(token.FOR) {
+		return nil
+	}
+
+	stmt.Expression = p.parseLoop(label)
+	if stmt.Expression == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.currToken, Left: left}
 	p.nextToken()
@@ -239,18 +405,76 @@ func (p *Parser) getDataType() token.TokenType {
 	return p.peekToken.Type
 }
 
+func isDataType(t token.TokenType) bool {
+	switch t {
+	case token.STRING, token.INT, token.BIGINT, token.FLOAT, token.BOOL, token.FUNCTION, token.ARRAY, token.HASH:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) Errors() []string {
+	errors := make([]string, 0, len(p.errors))
+	for _, err := range p.errors {
+		errors = append(errors, err.Error())
+	}
+	return errors
+}
+
+// ErrorList возвращает ошибки разбора вместе с их позициями
+func (p *Parser) ErrorList() []*Error {
 	return p.errors
 }
 
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
 		t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addErrorAt(p.peekToken.Pos, msg)
 }
 
 func (p *Parser) addError(msg string) {
-	p.errors = append(p.errors, msg)
+	p.addErrorAt(p.currToken.Pos, msg)
+}
+
+func (p *Parser) addErrorAt(pos token.Position, msg string) {
+	if p.recovering {
+		return
+	}
+	p.errors = append(p.errors, &Error{Pos: pos, Message: msg})
+}
+
+// skipStatement после ошибки, включившей recovering, пропускает токены до
+// конца инструкции: точки с запятой или закрывающей скобки блока, в котором
+// стоит инструкция. Текущим остается последний токен инструкции
+func (p *Parser) skipStatement() {
+	if !p.recovering {
+		return
+	}
+	p.recovering = false
+
+	depth := 0
+	for !p.currTokenIs(token.EOF) {
+		switch p.currToken.Type {
+		case token.LBRACE, token.LPAREN, token.LBRACKET:
+			depth++
+		case token.RBRACE, token.RPAREN, token.RBRACKET:
+			// скобки, открытые до ошибки, не считаются
+			if depth > 0 {
+				depth--
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				return
+			}
+		}
+
+		if depth == 0 && (p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.CASE) ||
+			p.peekTokenIs(token.DEFAULT) || p.peekTokenIs(token.EOF)) {
+			return
+		}
+		p.nextToken()
+	}
 }
 
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
@@ -285,6 +509,11 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	return leftExp
 }
 
+// parseIllegal пропускает недопустимый токен, ошибку о нем уже сообщил лексер
+func (p *Parser) parseIllegal() ast.Expression {
+	return nil
+}
+
 func (p *Parser) parseIdent() ast.Expression {
 	return &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 }
@@ -295,9 +524,15 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	}
 
 	i, err := strconv.ParseInt(p.currToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if big, ok := new(big.Int).SetString(p.currToken.Literal, 10); ok {
+			lit.Big = big
+			return lit
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.currToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	}
 
@@ -306,6 +541,23 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{
+		Token: p.currToken,
+	}
+
+	f, err := strconv.ParseFloat(p.currToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.currToken.Literal)
+		p.addError(msg)
+		return nil
+	}
+
+	lit.Value = f
+
+	return lit
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	expression := &ast.StringLiteral{
 		Token: p.currToken,
@@ -318,7 +570,7 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	stmt := &ast.PrefixExpression{
 		Token:    p.currToken,
-		Operator: p.currToken.Literal,
+		Operator: string(p.currToken.Type),
 	}
 
 	p.nextToken()
@@ -332,19 +584,87 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	stmt := &ast.InfixExpression{
 		Token:    p.currToken,
 		Left:     left,
-		Operator: p.currToken.Literal,
+		Operator: string(p.currToken.Type),
 	}
 
 	precedence := p.currPrecedences()
+	if p.currTokenIs(token.POWER) {
+		// возведение в степень правоассоциативно: 2 ** 3 ** 2 == 2 ** (3 ** 2)
+		precedence--
+	}
 	p.nextToken()
 	stmt.Right = p.parseExpression(precedence)
 
 	return stmt
 }
 
+// parseAssignExpression разбирает присваивание. Присваивание
+// правоассоциативно: a = b = 1 означает a = (b = 1)
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	exp := &ast.AssignExpression{
+		Token:    p.currToken,
+		Target:   target,
+		Operator: string(p.currToken.Type),
+	}
+
+	precedence := p.currPrecedences()
+	p.nextToken()
+	exp.Value = p.parseExpression(precedence - 1)
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case nil:
+		// ошибка в левой части уже записана
+		return nil
+	default:
+		p.addErrorAt(target.Pos(), fmt.Sprintf("нельзя присваивать значение выражению %s", target.String()))
+		return nil
+	}
+
+	return exp
+}
+
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
+	if isFormerIdent(p.currToken) {
+		p.formerIdentError(p.currToken)
+		return
+	}
+
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(msg)
+}
+
+// isFormerIdent сообщает, является ли токен словом, которое раньше было обычным
+// идентификатором: логическими операторами и, или, не или ключевым словом в
+func isFormerIdent(tok token.Token) bool {
+	switch tok.Type {
+	case token.AND, token.OR, token.BANG, token.IN:
+		return token.LookupIdent(tok.Literal) == tok.Type
+	default:
+		return false
+	}
+}
+
+// expectIdent переходит к следующему токену, если это идентификатор. Для слов,
+// ставших ключевыми, выдает понятную ошибку вместо общей
+func (p *Parser) expectIdent() bool {
+	if isFormerIdent(p.peekToken) {
+		p.nextToken()
+		p.formerIdentError(p.currToken)
+		return false
+	}
+	return p.expectPeek(token.IDENT)
+}
+
+func (p *Parser) formerIdentError(tok token.Token) {
+	kind := "логический оператор"
+	if tok.Type == token.IN {
+		kind = "ключевое слово"
+	}
+	msg := fmt.Sprintf("%q теперь %s и не может быть именем переменной, переименуйте переменную",
+		tok.Literal, kind)
+	p.addErrorAt(tok.Pos, msg)
+	p.recovering = true
 }
 
 func (p *Parser) parseBoolean() ast.Expression {
@@ -392,6 +712,16 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			elseIf := p.parseIfExpression()
+			if elseIf == nil {
+				return nil
+			}
+			exp.ElseIf = elseIf.(*ast.IfExpression)
+			return exp
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -402,18 +732,207 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseSwitchExpression() ast.Expression {
+	exp := &ast.SwitchExpression{
+		Token: p.currToken,
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	exp.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+
+	seen := make(map[string]bool)
+	for !p.currTokenIs(token.RBRACE) {
+		switch p.currToken.Type {
+		case token.CASE:
+			c := &ast.SwitchCase{Token: p.currToken}
+			c.Values = p.parseExpressionList(token.COLON)
+			if len(c.Values) == 0 {
+				p.addErrorAt(c.Token.Pos, "случай без значений")
+				p.skipSwitch()
+				return nil
+			}
+
+			for _, value := range c.Values {
+				key, ok := caseKey(value)
+				if !ok {
+					continue
+				}
+				if seen[key] {
+					p.addErrorAt(value.Pos(), fmt.Sprintf("значение %s уже указано в другом случае", value.String()))
+					p.skipSwitch()
+					return nil
+				}
+				seen[key] = true
+			}
+
+			c.Body = p.parseCaseBody()
+			exp.Cases = append(exp.Cases, c)
+		case token.DEFAULT:
+			if exp.Default != nil {
+				p.addError("повторная ветка по_умолчанию")
+				p.skipSwitch()
+				return nil
+			}
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+			exp.Default = p.parseCaseBody()
+		default:
+			p.addError(fmt.Sprintf("ожидалось случай, по_умолчанию или }, получено %s", p.currToken.Type))
+			p.skipSwitch()
+			return nil
+		}
+	}
+
+	return exp
+}
+
+// skipSwitch пропускает оставшиеся ветки выбора до его закрывающей скобки,
+// чтобы после ошибки в ветке не сообщать о лишних ошибках
+func (p *Parser) skipSwitch() {
+	depth := 1
+	for !p.currTokenIs(token.EOF) {
+		switch p.currToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// parseCaseBody разбирает инструкции ветки выбора до следующей ветки
+// или закрывающей скобки. Текущий токен - двоеточие
+func (p *Parser) parseCaseBody() *ast.BlockStatement {
+	block := &ast.BlockStatement{
+		Token:      p.currToken,
+		Statements: make([]ast.Statement, 0),
+	}
+
+	p.nextToken()
+
+	for !p.currTokenIs(token.CASE) && !p.currTokenIs(token.DEFAULT) &&
+		!p.currTokenIs(token.RBRACE) && !p.currTokenIs(token.EOF) {
+		stmt := p.parseStatement()
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		p.skipStatement()
+		p.nextToken()
+	}
+
+	return block
+}
+
+// caseKey возвращает ключ для поиска повторяющихся литералов в ветках выбора.
+// Для выражений, значение которых известно только при выполнении, ok = false
+func caseKey(exp ast.Expression) (string, bool) {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		if exp.Big != nil {
+			return "число " + exp.Big.String(), true
+		}
+		return fmt.Sprintf("число %d", exp.Value), true
+	case *ast.StringLiteral:
+		return "строка " + strconv.Quote(exp.Value), true
+	case *ast.BooleanLiteral:
+		return fmt.Sprintf("булев %t", exp.Value), true
+	default:
+		return "", false
+	}
+}
+
 func (p *Parser) parseForLoopExpression() ast.Expression {
+	return p.parseLoop(nil)
+}
+
+func (p *Parser) parseLoop(label *ast.Identifier) ast.Expression {
 	exp := &ast.ForLoopExpression{
 		Token: p.currToken,
+		Label: label,
 	}
 
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 
+	p.nextToken()
+	isIdent := p.currTokenIs(token.IDENT) || isFormerIdent(p.currToken)
+	if isIdent && (p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA)) {
+		return p.parseForEachExpression(exp.Token, label)
+	}
+
+	switch p.currToken.Type {
+	case token.LET:
+		init := p.parseVariableStatement()
+		if init == nil {
+			return nil
+		}
+		if !p.currTokenIs(token.SEMICOLON) {
+			p.addError(fmt.Sprintf("expected next token to be %s, got %s instead",
+				token.SEMICOLON, p.currToken.Type))
+			return nil
+		}
+		exp.Init = init
+		return p.parseThreePartLoop(exp)
+	case token.SEMICOLON:
+		return p.parseThreePartLoop(exp)
+	}
+
+	first := p.currToken
+	condition := p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		exp.Init = &ast.ExpressionStatement{Token: first, Expression: condition}
+		return p.parseThreePartLoop(exp)
+	}
+	exp.Condition = condition
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Statement = p.parseLoopBody(label)
+
+	return exp
+}
+
+// parseThreePartLoop разбирает условие и завершающее выражение цикла
+// вида цикл (init; условие; post). Текущий токен - точка с запятой после init
+func (p *Parser) parseThreePartLoop(exp *ast.ForLoopExpression) ast.Expression {
 	p.nextToken()
 	exp.Condition = p.parseExpression(LOWEST)
 
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		exp.Post = p.parseExpression(LOWEST)
+	}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
@@ -422,11 +941,68 @@ func (p *Parser) parseForLoopExpression() ast.Expression {
 		return nil
 	}
 
-	exp.Statement = p.parseBlockStatement()
+	exp.Statement = p.parseLoopBody(exp.Label)
 
 	return exp
 }
 
+// parseForEachExpression разбирает перебор коллекции, начиная с первой
+// переменной после открывающей скобки: цикл (i, x в мас) { ... }
+func (p *Parser) parseForEachExpression(tok token.Token, label *ast.Identifier) ast.Expression {
+	if isFormerIdent(p.currToken) {
+		p.formerIdentError(p.currToken)
+		return nil
+	}
+
+	exp := &ast.ForEachExpression{
+		Token: tok,
+		Label: label,
+		Value: &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal},
+	}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectIdent() {
+			return nil
+		}
+		exp.Index = exp.Value
+		exp.Value = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	exp.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Statement = p.parseLoopBody(label)
+
+	return exp
+}
+
+// parseLoopBody разбирает тело цикла, запоминая его метку для прервать/продолжить
+func (p *Parser) parseLoopBody(label *ast.Identifier) *ast.BlockStatement {
+	name := ""
+	if label != nil {
+		name = label.Value
+	}
+
+	p.loops = append(p.loops, name)
+	body := p.parseBlockStatement()
+	p.loops = p.loops[:len(p.loops)-1]
+
+	return body
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{
 		Token:      p.currToken,
@@ -440,6 +1016,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
+		p.skipStatement()
 		p.nextToken()
 	}
 
@@ -451,46 +1028,176 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		Token: p.currToken,
 	}
 
-	if !p.expectPeek(token.LPAREN) {
+	if !p.parseFunction(exp) {
 		return nil
 	}
 
-	exp.Arguments = p.parseFunctionArguments()
+	return exp
+}
 
-	if !p.expectPeek(token.LBRACE) {
+// parseFunctionDeclaration разбирает объявление функция имя(x) { ... }
+func (p *Parser) parseFunctionDeclaration() ast.Statement {
+	stmt := &ast.FunctionDeclaration{
+		Token: p.currToken,
+	}
+
+	if !p.expectIdent() {
 		return nil
 	}
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	stmt.Function = &ast.FunctionLiteral{Token: stmt.Token}
+	if !p.parseFunction(stmt.Function) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseFunction разбирает аргументы, тип результата и тело функции,
+// начиная с токена перед (
+func (p *Parser) parseFunction(exp *ast.FunctionLiteral) bool {
+	if !p.expectPeek(token.LPAREN) {
+		return false
+	}
+
+	if !p.parseFunctionArguments(exp) {
+		return false
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.parseTypeAnnotation() {
+			return false
+		}
+		exp.ReturnType = p.currToken.Type
+	}
 
+	if !p.expectPeek(token.LBRACE) {
+		return false
+	}
+
+	// прервать и продолжить не выходят за пределы функции
+	loops := p.loops
+	p.loops = nil
 	exp.Body = p.parseBlockStatement()
+	p.loops = loops
 
-	return exp
+	markTailReturns(exp.Body)
+
+	return true
 }
 
-func (p *Parser) parseFunctionArguments() []*ast.Identifier {
-	args := make([]*ast.Identifier, 0)
+// markTailReturns отмечает инструкции вернуть, результат которых сразу
+// возвращается из функции. Вернуть внутри выражения, например в аргументе
+// вызова, не отмечается: его результат используется как значение
+func markTailReturns(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+
+	for _, statement := range block.Statements {
+		switch statement := statement.(type) {
+		case *ast.ReturnStatement:
+			statement.Tail = true
+		case *ast.BlockStatement:
+			markTailReturns(statement)
+		case *ast.ExpressionStatement:
+			markTailExpression(statement.Expression)
+		}
+	}
+}
+
+func markTailExpression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.IfExpression:
+		for ; exp != nil; exp = exp.ElseIf {
+			markTailReturns(exp.Consequence)
+			markTailReturns(exp.Alternative)
+		}
+	case *ast.SwitchExpression:
+		for _, c := range exp.Cases {
+			markTailReturns(c.Body)
+		}
+		markTailReturns(exp.Default)
+	case *ast.ForLoopExpression:
+		markTailReturns(exp.Statement)
+	case *ast.ForEachExpression:
+		markTailReturns(exp.Statement)
+	}
+}
+
+// parseFunctionArguments разбирает аргументы функции вида
+// (x: число, y = 10, ...остальные) и записывает их в exp
+func (p *Parser) parseFunctionArguments(exp *ast.FunctionLiteral) bool {
+	exp.Arguments = make([]*ast.Identifier, 0)
+	exp.ArgumentTypes = make([]token.TokenType, 0)
+	exp.Defaults = make([]ast.Expression, 0)
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
+		return true
 	}
 
-	p.nextToken()
+	for {
+		if exp.Variadic {
+			p.addErrorAt(p.peekToken.Pos, "аргумент с ... должен быть последним")
+			return false
+		}
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			exp.Variadic = true
+		}
 
-	ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
-	args = append(args, ident)
+		if !p.expectIdent() {
+			return false
+		}
+		ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+		var dataType token.TokenType
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			if !p.parseTypeAnnotation() {
+				return false
+			}
+			dataType = p.currToken.Type
+		}
+
+		var value ast.Expression
+		if p.peekTokenIs(token.ASSIGN) && !exp.Variadic {
+			p.nextToken()
+			p.nextToken()
+			value = p.parseExpression(LOWEST)
+		} else if !exp.Variadic && len(exp.Defaults) > 0 && exp.Defaults[len(exp.Defaults)-1] != nil {
+			p.addErrorAt(ident.Pos(), fmt.Sprintf("аргумент %s без значения по умолчанию не может идти после аргументов со значением", ident.Value))
+			return false
+		}
+
+		exp.Arguments = append(exp.Arguments, ident)
+		exp.ArgumentTypes = append(exp.ArgumentTypes, dataType)
+		exp.Defaults = append(exp.Defaults, value)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
-		args = append(args, ident)
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
+	return p.expectPeek(token.RPAREN)
+}
 
-	return args
+// parseTypeAnnotation переходит к типу данных после двоеточия
+func (p *Parser) parseTypeAnnotation() bool {
+	if !isDataType(p.getDataType()) {
+		p.addErrorAt(p.peekToken.Pos, "missing data type")
+		return false
+	}
+	p.nextToken()
+	return true
 }
 
 func (p *Parser) parseCallExpression(expression ast.Expression) ast.Expression {
@@ -549,3 +1256,34 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	}
 	return list
 }
+
+// parseHashLiteral разбирает словарь { ключ: значение, ... }. Блоки кода
+// разбираются только после если, цикл и функция, поэтому { в позиции
+// выражения всегда открывает словарь
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currToken, Pairs: make([]ast.HashPair, 0)}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"testing"
+
+	"github.com/usamaroman/uman/ast"
 )
 
 func TestReturnStatement(t *testing.T) {
@@ -44,3 +46,59 @@ func TestReturnStatement(t *testing.T) {
 	//}
 
 }
+
+func TestTailReturns(t *testing.T) {
+	// тело функции f из input
+	body := func(t *testing.T, input string) *ast.BlockStatement {
+		p := New(input)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		decl, ok := program.Statements[0].(*ast.FunctionDeclaration)
+		if !ok {
+			t.Fatalf("stmt is not *ast.FunctionDeclaration. got=%T", program.Statements[0])
+		}
+		return decl.Function.Body
+	}
+
+	// первая инструкция блока, разобранного из выражения
+	firstReturn := func(t *testing.T, block *ast.BlockStatement) *ast.ReturnStatement {
+		stmt, ok := block.Statements[0].(*ast.ReturnStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ReturnStatement. got=%T", block.Statements[0])
+		}
+		return stmt
+	}
+
+	fn := body(t, "функция f() { вернуть f(); }")
+	if !firstReturn(t, fn).Tail {
+		t.Errorf("return in function body is not tail")
+	}
+
+	fn = body(t, "функция f(n) { если (n) { 1 } иначе если (n > 1) { вернуть f(); } }")
+	ifExp := fn.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if !firstReturn(t, ifExp.ElseIf.Consequence).Tail {
+		t.Errorf("return in else if branch is not tail")
+	}
+
+	fn = body(t, "функция f(n) { цикл (x в 0..n) { выбор (x) { случай 1: вернуть f(); } } }")
+	loop := fn.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ForEachExpression)
+	switchExp := loop.Statement.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.SwitchExpression)
+	if !firstReturn(t, switchExp.Cases[0].Body).Tail {
+		t.Errorf("return in switch case inside loop is not tail")
+	}
+
+	// результат вернуть внутри выражения используется как значение
+	fn = body(t, "функция f() { вывести(если (истина) { вернуть f(); }); }")
+	call := fn.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if firstReturn(t, call.Arguments[0].(*ast.IfExpression).Consequence).Tail {
+		t.Errorf("return inside call argument is tail")
+	}
+
+	p := New("вернуть 1;")
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program.Statements[0].(*ast.ReturnStatement).Tail {
+		t.Errorf("return outside function is tail")
+	}
+}
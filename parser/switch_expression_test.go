@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/usamaroman/uman/ast"
+)
+
+func TestSwitchExpression(t *testing.T) {
+	input := `
+выбор (x) {
+    случай 1, 2:
+        вывести("мало");
+    случай "три":
+        вывести("три");
+        вывести("строкой");
+    по_умолчанию:
+        вывести("много");
+}
+`
+
+	p := New(input)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.SwitchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.SwitchExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Subject, "x") {
+		return
+	}
+	if len(exp.Cases) != 2 {
+		t.Fatalf("exp.Cases does not contain 2 cases. got=%d", len(exp.Cases))
+	}
+
+	first := exp.Cases[0]
+	if len(first.Values) != 2 {
+		t.Fatalf("first case does not contain 2 values. got=%d", len(first.Values))
+	}
+	testIntegerLiteral(t, first.Values[0], 1)
+	testIntegerLiteral(t, first.Values[1], 2)
+	if len(first.Body.Statements) != 1 {
+		t.Errorf("first case body is not 1 statement. got=%d", len(first.Body.Statements))
+	}
+
+	second := exp.Cases[1]
+	testStringVar(t, second.Values[0], "три")
+	if len(second.Body.Statements) != 2 {
+		t.Errorf("second case body is not 2 statements. got=%d", len(second.Body.Statements))
+	}
+
+	if exp.Default == nil || len(exp.Default.Statements) != 1 {
+		t.Errorf("wrong default branch. got=%+v", exp.Default)
+	}
+}
+
+func TestSwitchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"выбор (x) { случай 1: 1; случай 2, 1: 2; }", []string{"1:36: значение 1 уже указано в другом случае"}},
+		{`выбор (x) { случай "а", "а": 1; } x;`, []string{`1:25: значение а уже указано в другом случае`}},
+		{"выбор (x) { случай 1: если (x) { 1 } случай 1: если (x) { 2 } по_умолчанию: 3; } x;", []string{"1:45: значение 1 уже указано в другом случае"}},
+		{"выбор (x) { по_умолчанию: 1; по_умолчанию: 2; }", []string{"1:30: повторная ветка по_умолчанию"}},
+		{"выбор (x) { случай : 1; }", []string{"1:13: случай без значений"}},
+		{"выбор (x) { 1; }", []string{"1:13: ожидалось случай, по_умолчанию или }, получено INT_VAL"}},
+	}
+
+	for _, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("wrong errors for %q. expected=%q, got=%q", tt.input, tt.expected, errors)
+			continue
+		}
+		for i, err := range errors {
+			if err != tt.expected[i] {
+				t.Errorf("wrong error. expected=%q, got=%q", tt.expected[i], err)
+			}
+		}
+	}
+}
@@ -12,17 +12,25 @@ import (
 	"github.com/usamaroman/uman/evaluator"
 	"github.com/usamaroman/uman/object"
 	"github.com/usamaroman/uman/parser"
+	"github.com/usamaroman/uman/token"
 )
 
 var ErrWrongExtension = errors.New("wrong file extension")
 
-func Run() {
+// Run запускает REPL. maxCallDepth ограничивает глубину вызовов функций,
+// 0 - предел по умолчанию
+func Run(maxCallDepth int) {
 	const prompt = ">> "
 	scanner := bufio.NewScanner(os.Stdin)
 	out := os.Stdout
 	env := object.NewEnvironment()
+	env.SetMaxCallDepth(maxCallDepth)
 
-	for {
+	// каждый ввод хранится под своим именем: функция, созданная в одном
+	// вводе, может вызвать ошибку при выполнении другого
+	sources := make(map[string]string)
+
+	for count := 1; ; {
 		fmt.Printf(prompt)
 		scanned := scanner.Scan()
 		if !scanned {
@@ -30,56 +38,92 @@ func Run() {
 		}
 
 		line := scanner.Text()
-		p := parser.New(line)
+		if runCommand(out, line, env) {
+			continue
+		}
+
+		name := fmt.Sprintf("<ввод %d>", count)
+		count++
+		sources[name] = line
+
+		p := parser.NewFile(name, line)
 
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+			printParserErrors(out, sources, p.ErrorList())
 			continue
 		}
 
 		evaluated := evaluator.Eval(program, env)
 		if evaluated != nil {
+			if err, ok := evaluated.(*object.Error); ok {
+				printError(out, sources, err.Pos, err.Message)
+				printTrace(out, err.Trace)
+				continue
+			}
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
 		}
 	}
 }
 
-func ReadFile(filename string) {
+// runCommand выполняет служебную команду REPL и сообщает, была ли line командой.
+//
+//	:тип x  печатает тип переменной x
+func runCommand(out io.Writer, line string, env *object.Environment) bool {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, ":") {
+		return false
+	}
+
+	fields := strings.Fields(line)
+	switch {
+	case fields[0] == ":тип" && len(fields) == 2:
+		if dataType, ok := evaluator.VariableType(env, fields[1]); ok {
+			io.WriteString(out, dataType+"\n")
+		} else {
+			io.WriteString(out, "нет переменной: "+fields[1]+"\n")
+		}
+	default:
+		io.WriteString(out, "неизвестная команда: "+line+"\n")
+	}
+
+	return true
+}
+
+// ReadFile выполняет программу из файла filename. maxCallDepth ограничивает
+// глубину вызовов функций, 0 - предел по умолчанию
+func ReadFile(filename string, maxCallDepth int) {
 	err := readFileExtension(filename)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	file, err := os.Open(filename)
+	source, err := os.ReadFile(filename)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	scanner := bufio.NewScanner(file)
 	out := os.Stdout
 	env := object.NewEnvironment()
+	env.SetMaxCallDepth(maxCallDepth)
+	input := string(source)
+	sources := map[string]string{filename: input}
 
-	var line string
-
-	for {
-		scanned := scanner.Scan()
-		if !scanned {
-			break
-		}
-
-		line += scanner.Text()
-	}
-
-	p := parser.New(line)
+	p := parser.NewFile(filename, input)
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		printParserErrors(out, p.Errors())
+		printParserErrors(out, sources, p.ErrorList())
+		return
 	}
 
 	evaluated := evaluator.Eval(program, env)
 	if evaluated != nil {
+		if err, ok := evaluated.(*object.Error); ok {
+			printError(out, sources, err.Pos, err.Message)
+			printTrace(out, err.Trace)
+			return
+		}
 		fmt.Println(evaluated.Inspect())
 	}
 }
@@ -94,8 +138,68 @@ func readFileExtension(filename string) error {
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
+func printParserErrors(out io.Writer, sources map[string]string, errors []*parser.Error) {
+	for _, err := range errors {
+		printError(out, sources, err.Pos, err.Message)
+	}
+}
+
+// printError печатает сообщение об ошибке и строку исходного текста,
+// в которой она возникла, подчеркивая нужный столбец знаком ^. Текст берется
+// из sources по имени файла в pos; если его там нет, строка не печатается
+//
+//	test.um:3:9: нет переменной: x
+//	   3 | вывести(x);
+//	     |         ^
+func printError(out io.Writer, sources map[string]string, pos token.Position, msg string) {
+	if !pos.IsValid() {
 		io.WriteString(out, "\t"+msg+"\n")
+		return
+	}
+
+	io.WriteString(out, pos.String()+": "+msg+"\n")
+
+	source, ok := sources[pos.Filename]
+	if !ok {
+		return
+	}
+	lines := strings.Split(source, "\n")
+	if pos.Line > len(lines) {
+		return
+	}
+	line := []rune(strings.TrimRight(lines[pos.Line-1], "\r"))
+
+	var caret strings.Builder
+	for i := 0; i < pos.Column-1 && i < len(line); i++ {
+		if line[i] == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+
+	gutter := fmt.Sprintf("%4d | ", pos.Line)
+	io.WriteString(out, gutter+string(line)+"\n")
+	io.WriteString(out, strings.Repeat(" ", len(gutter)-2)+"| "+caret.String()+"\n")
+}
+
+// printTrace печатает стек вызовов, в котором возникла ошибка
+//
+//	стек вызовов (последний вызов первым):
+//	    делить(10, 0) в test.um:3:5
+//	    главная() в test.um:10:1
+func printTrace(out io.Writer, trace []*object.Frame) {
+	if len(trace) == 0 {
+		return
+	}
+
+	io.WriteString(out, "стек вызовов (последний вызов первым):\n")
+	for _, frame := range trace {
+		io.WriteString(out, "    "+frame.String()+"\n")
+	}
+
+	if rest := trace[len(trace)-1].Depth - 1; rest > 0 {
+		io.WriteString(out, fmt.Sprintf("    ... еще вызовов: %d\n", rest))
 	}
 }
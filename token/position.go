@@ -0,0 +1,31 @@
+package token
+
+import "fmt"
+
+// Position описывает место в исходном тексте
+type Position struct {
+	Filename string
+	Offset   int // смещение в байтах, начиная с 0
+	Line     int // номер строки, начиная с 1
+	Column   int // номер столбца в символах, начиная с 1
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String возвращает позицию в виде "файл:строка:столбец"
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.Filename != "" {
+			return p.Filename
+		}
+		return "-"
+	}
+
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
@@ -13,15 +13,28 @@ const (
 	MINUS    = "-"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+	POWER    = "**"
 	GT       = ">"
 	EGT      = ">="
 	LT       = "<"
 	ELT      = "<="
 	BANG     = "!"
 	NEQ      = "!="
+	AND      = "&&"
+	OR       = "||"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
 
 	LET       = "LET"
+	CONST     = "CONST"
 	COLON     = ":"
+	DOTDOT    = ".."
+	ELLIPSIS  = "..."
 	COMMA     = ","
 	SEMICOLON = ";"
 	LPAREN    = "("
@@ -33,6 +46,7 @@ const (
 
 	STRING_VAL = "STRING_VAL"
 	INT_VAL    = "INT_VAL"
+	FLOAT_VAL  = "FLOAT_VAL"
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -42,30 +56,54 @@ const (
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
 	FOR      = "FOR"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IN       = "IN"
+	SWITCH   = "SWITCH"
+	CASE     = "CASE"
+	DEFAULT  = "DEFAULT"
 	INT      = "INT"
+	BIGINT   = "BIGINT"
+	FLOAT    = "FLOAT"
 	STRING   = "STRING"
 	BOOL     = "BOOL"
 	ARRAY    = "ARRAY"
+	HASH     = "HASH"
 )
 
 var Keywords = map[string]TokenType{
-	"создать": LET,
-	"функция": FUNCTION,
-	"истина":  TRUE,
-	"ложь":    FALSE,
-	"если":    IF,
-	"иначе":   ELSE,
-	"вернуть": RETURN,
-	"цикл":    FOR,
-	"число":   INT,
-	"строка":  STRING,
-	"булев":   BOOL,
-	"массив":  ARRAY,
+	"создать":       LET,
+	"постоянная":    CONST,
+	"функция":       FUNCTION,
+	"истина":        TRUE,
+	"ложь":          FALSE,
+	"если":          IF,
+	"иначе":         ELSE,
+	"вернуть":       RETURN,
+	"цикл":          FOR,
+	"прервать":      BREAK,
+	"продолжить":    CONTINUE,
+	"в":             IN,
+	"выбор":         SWITCH,
+	"случай":        CASE,
+	"по_умолчанию":  DEFAULT,
+	"число":         INT,
+	"большое_число": BIGINT,
+	"дробь":         FLOAT,
+	"строка":        STRING,
+	"булев":         BOOL,
+	"массив":        ARRAY,
+	"словарь":       HASH,
+	"и":             AND,
+	"или":           OR,
+	"не":            BANG,
 }
 
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type     TokenType
+	Literal  string
+	Pos      Position
+	Comments []string // комментарии, стоящие перед токеном
 }
 
 func New(tokenType TokenType, literal rune) Token {
@@ -81,3 +119,13 @@ func LookupIdent(literal string) TokenType {
 	}
 	return IDENT
 }
+
+// Keyword возвращает ключевое слово, которым записывается токен t
+func Keyword(t TokenType) string {
+	for literal, tok := range Keywords {
+		if tok == t {
+			return literal
+		}
+	}
+	return string(t)
+}